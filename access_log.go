@@ -0,0 +1,152 @@
+package easyserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogokit/logs"
+)
+
+// AccessLogFormat 访问日志格式, 除AccessLogCombined和AccessLogJSON外的值均视为自定义模板,
+// 模板中可使用的占位符有: ${time} ${remote_addr} ${method} ${uri} ${path} ${proto} ${status}
+// ${bytes} ${latency} ${referer} ${user_agent} ${log_id}
+type AccessLogFormat string
+
+const (
+	// nginx的combined格式, 末尾追加了请求耗时
+	AccessLogCombined AccessLogFormat = "combined"
+	// 每个请求输出一行json
+	AccessLogJSON AccessLogFormat = "json"
+)
+
+// AccessLogMiddleware 返回在请求处理完成后向w输出访问日志的中间件, w可以不是并发安全的
+func AccessLogMiddleware(w io.Writer, format AccessLogFormat) func(c Context) {
+	if w == nil {
+		panic("access log writer must not be nil")
+	}
+	var mu sync.Mutex
+	return func(c Context) {
+		start := requestStart(c)
+		defer func() {
+			line := formatAccessLog(c, format, start, time.Since(start))
+			mu.Lock()
+			defer mu.Unlock()
+			_, _ = io.WriteString(w, line)
+		}()
+		c.Next()
+	}
+}
+
+// JSONAccessLogMiddleware 返回在请求处理完成后向w输出一行json访问日志的中间件, 可直接被Elasticsearch、Loki等采集,
+// w可以不是并发安全的. request_id为请求的log id, route为匹配的路由模式, 未匹配路由时为空
+func JSONAccessLogMiddleware(w io.Writer) func(c Context) {
+	if w == nil {
+		panic("access log writer must not be nil")
+	}
+	var mu sync.Mutex
+	return func(c Context) {
+		start := requestStart(c)
+		defer func() {
+			req := c.GetReq()
+			b, _ := json.Marshal(&struct {
+				Timestamp    string  `json:"timestamp"`
+				Method       string  `json:"method"`
+				Path         string  `json:"path"`
+				Status       int     `json:"status"`
+				LatencyMs    float64 `json:"latency_ms"`
+				BytesWritten int     `json:"bytes_written"`
+				ClientIP     string  `json:"client_ip"`
+				UserAgent    string  `json:"user_agent"`
+				RequestId    string  `json:"request_id"`
+				Route        string  `json:"route"`
+			}{
+				Timestamp:    start.Format(time.RFC3339Nano),
+				Method:       req.Method,
+				Path:         req.URL.Path,
+				Status:       c.StatusCode(),
+				LatencyMs:    float64(time.Since(start)) / float64(time.Millisecond),
+				BytesWritten: c.WrittenBytes(),
+				ClientIP:     c.ClientIP(),
+				UserAgent:    req.UserAgent(),
+				RequestId:    logs.GetLogId(req.Context()),
+				Route:        c.FullPath(),
+			})
+			b = append(b, '\n')
+			mu.Lock()
+			defer mu.Unlock()
+			_, _ = w.Write(b)
+		}()
+		c.Next()
+	}
+}
+
+func formatAccessLog(c Context, format AccessLogFormat, start time.Time, latency time.Duration) string {
+	req := c.GetReq()
+	remoteAddr := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		remoteAddr = host
+	}
+
+	switch format {
+	case AccessLogCombined:
+		return fmt.Sprintf("%s - - [%s] \"%s %s %s\" %d %d \"%s\" \"%s\" %.3fms\n",
+			remoteAddr, start.Format("02/Jan/2006:15:04:05 -0700"), req.Method, req.RequestURI, req.Proto,
+			c.StatusCode(), c.WrittenBytes(), req.Referer(), req.UserAgent(), float64(latency)/float64(time.Millisecond))
+	case AccessLogJSON:
+		b, _ := json.Marshal(&struct {
+			Time       string  `json:"time"`
+			RemoteAddr string  `json:"remote_addr"`
+			Method     string  `json:"method"`
+			URI        string  `json:"uri"`
+			Proto      string  `json:"proto"`
+			Status     int     `json:"status"`
+			Bytes      int     `json:"bytes"`
+			LatencyMs  float64 `json:"latency_ms"`
+			Referer    string  `json:"referer"`
+			UserAgent  string  `json:"user_agent"`
+			LogId      string  `json:"log_id"`
+		}{
+			Time:       start.Format(time.RFC3339Nano),
+			RemoteAddr: remoteAddr,
+			Method:     req.Method,
+			URI:        req.RequestURI,
+			Proto:      req.Proto,
+			Status:     c.StatusCode(),
+			Bytes:      c.WrittenBytes(),
+			LatencyMs:  float64(latency) / float64(time.Millisecond),
+			Referer:    req.Referer(),
+			UserAgent:  req.UserAgent(),
+			LogId:      logs.GetLogId(req.Context()),
+		})
+		return string(b) + "\n"
+	default:
+		return strings.NewReplacer(
+			"${time}", start.Format(time.RFC3339),
+			"${remote_addr}", remoteAddr,
+			"${method}", req.Method,
+			"${uri}", req.RequestURI,
+			"${path}", req.URL.Path,
+			"${proto}", req.Proto,
+			"${status}", strconv.Itoa(c.StatusCode()),
+			"${bytes}", strconv.Itoa(c.WrittenBytes()),
+			"${latency}", latency.String(),
+			"${referer}", req.Referer(),
+			"${user_agent}", req.UserAgent(),
+			"${log_id}", logs.GetLogId(req.Context()),
+		).Replace(string(format)) + "\n"
+	}
+}
+
+// requestStart 返回ServeHTTP开始处理请求的时间, 使访问日志与Server-Timing的耗时一致, 无法获取时返回当前时间
+func requestStart(c Context) time.Time {
+	if rc, ok := c.(*reqContext); ok && !rc.start.IsZero() {
+		return rc.start
+	}
+	return time.Now()
+}
@@ -0,0 +1,72 @@
+package easyserver
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// WrapH 将http.Handler转换为可通过Register注册的handler, 此时Context.HandlerName返回WrapH内部函数的名称,
+// 可通过Node.Name指定路由名称, 或使用Handle注册
+func WrapH(h http.Handler) func(c Context) {
+	if h == nil {
+		panic("handler must not be nil")
+	}
+	return func(c Context) {
+		h.ServeHTTP(c.GetResp(), c.GetReq())
+	}
+}
+
+// WrapF 将http.HandlerFunc转换为可通过Register注册的handler
+func WrapF(f http.HandlerFunc) func(c Context) {
+	if f == nil {
+		panic("handler must not be nil")
+	}
+	return WrapH(f)
+}
+
+type reqContextKey struct{}
+
+// httpMiddlewareCall 保存一次UseHTTPMiddleware中间件调用对应的Context
+type httpMiddlewareCall struct {
+	mu sync.Mutex
+	c  *reqContext
+	// mw已返回, c可能已被回收
+	returned bool
+}
+
+// UseHTTPMiddleware 通过net/http风格中间件的next调用衔接Context.Next:
+// mw传给next的*http.Request和http.ResponseWriter在后续中间件和handler中可通过GetReq和GetResp获取,
+// next返回后恢复为调用mw之前的值; 路径参数等保存在Context中的数据不受影响; mw未调用next时后续中间件和handler不会执行.
+// mw需在返回前同步调用next: mw返回时next仍在其他goroutine中执行(如http.TimeoutHandler超时)则等待其执行结束,
+// mw返回后才调用的next不执行后续中间件和handler
+func (e *engine) UseHTTPMiddleware(mw func(http.Handler) http.Handler) {
+	if mw == nil {
+		panic("middleware must not be nil")
+	}
+	h := mw(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		call := req.Context().Value(reqContextKey{}).(*httpMiddlewareCall)
+		call.mu.Lock()
+		defer call.mu.Unlock()
+		if call.returned {
+			return
+		}
+		c := call.c
+		oldReq, oldResp := c.req, c.resp
+		c.req, c.resp = req, resp
+		defer func() {
+			c.req, c.resp = oldReq, oldResp
+		}()
+		c.Next()
+	}))
+	e.AppendMiddleware(func(c Context) {
+		rc := c.(*reqContext)
+		call := &httpMiddlewareCall{c: rc}
+		defer func() {
+			call.mu.Lock()
+			call.returned = true
+			call.mu.Unlock()
+		}()
+		h.ServeHTTP(rc.resp, rc.req.WithContext(context.WithValue(rc.req.Context(), reqContextKey{}, call)))
+	})
+}
@@ -0,0 +1,60 @@
+package easyserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 需使用go test -race运行: http.TimeoutHandler在另一个goroutine中执行next, 超时返回后Context不能被回收
+func TestUseHTTPMiddlewareTimeoutHandler(t *testing.T) {
+	e := newTestEngine()
+	e.UseHTTPMiddleware(func(next http.Handler) http.Handler {
+		return http.TimeoutHandler(next, 10*time.Millisecond, "timeout")
+	})
+	e.GET("/slow/:id", func(c Context) {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = c.GetResp().Write([]byte(c.Param("id").(string)))
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow/1", nil))
+			if w.Code != http.StatusServiceUnavailable {
+				t.Errorf("expected 503 from TimeoutHandler, got %d", w.Code)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// mw返回后才调用的next不执行handler
+func TestUseHTTPMiddlewareNextAfterReturn(t *testing.T) {
+	e := newTestEngine()
+	var wg sync.WaitGroup
+	e.UseHTTPMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				time.Sleep(10 * time.Millisecond)
+				next.ServeHTTP(w, req)
+			}()
+		})
+	})
+	var called bool
+	e.GET("/late", func(c Context) { called = true })
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/late", nil))
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/late", nil))
+	wg.Wait()
+	if called {
+		t.Fatal("handler ran after the middleware had returned")
+	}
+}
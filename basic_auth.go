@@ -0,0 +1,48 @@
+package easyserver
+
+import (
+	"net/http"
+	"strconv"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// UserKey BasicAuthMiddleware通过Context.Set保存鉴权通过的用户名使用的key
+const UserKey = "user"
+
+// BasicAuthMiddleware 返回HTTP basic auth鉴权中间件, credentials为用户名到bcrypt哈希后的密码的映射.
+// 鉴权通过时将用户名通过Context.Set(UserKey, username)保存, 失败时响应401及WWW-Authenticate
+func BasicAuthMiddleware(realm string, credentials map[string]string) func(c Context) {
+	if realm == "" {
+		realm = "Authorization Required"
+	}
+	challenge := "Basic realm=" + strconv.Quote(realm)
+	// 用户名不存在时与dummyHash比较, 使耗时与用户名存在时一致, 避免通过响应时间枚举用户名
+	cost := bcrypt.DefaultCost
+	for _, v := range credentials {
+		if n, err := bcrypt.Cost([]byte(v)); err == nil && n > cost {
+			cost = n
+		}
+	}
+	dummyHash, err := bcrypt.GenerateFromPassword([]byte("easyserver-basic-auth-dummy"), cost)
+	if err != nil {
+		panic("generate dummy bcrypt hash failed: " + err.Error())
+	}
+	return func(c Context) {
+		username, password, ok := c.GetReq().BasicAuth()
+		if ok {
+			hash, exist := credentials[username]
+			if !exist {
+				hash = string(dummyHash)
+			}
+			ok = bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil && exist
+		}
+		if !ok {
+			c.GetResp().Header().Set("WWW-Authenticate", challenge)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Set(UserKey, username)
+		c.Next()
+	}
+}
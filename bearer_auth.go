@@ -0,0 +1,43 @@
+package easyserver
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// ClaimsKey BearerAuthMiddleware通过Context.Set保存claims使用的key
+const ClaimsKey = "claims"
+
+// BearerAuthMiddleware 返回Bearer token鉴权中间件: 从Authorization请求头中提取token并调用validator校验,
+// 校验通过时将返回的claims通过Context.Set(ClaimsKey, claims)保存. 缺少token或校验失败时响应401.
+// excludePaths中的请求路径不做鉴权, 与路由查找一样按SetCleanPath、SetCaseInsensitiveRouting匹配请求路径
+func BearerAuthMiddleware(validator func(ctx context.Context, token string) (claims interface{}, err error), excludePaths ...string) func(c Context) {
+	if validator == nil {
+		panic("validator must not be nil")
+	}
+	excluded := newPathSet(excludePaths)
+
+	return func(c Context) {
+		if excluded.has(c) {
+			c.Next()
+			return
+		}
+
+		auth := c.GetReq().Header.Get("Authorization")
+		const prefix = "Bearer "
+		if len(auth) <= len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		claims, err := validator(c, strings.TrimSpace(auth[len(prefix):]))
+		if err != nil {
+			c.GetResp().Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Set(ClaimsKey, claims)
+		c.Next()
+	}
+}
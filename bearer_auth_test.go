@@ -0,0 +1,41 @@
+package easyserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// 排除路径与路由使用相同的路径匹配, 不能通过改变大小写或添加多余的'/'绕过鉴权
+func TestBearerAuthExcludedPathsMatchRoutingPath(t *testing.T) {
+	validator := func(ctx context.Context, token string) (interface{}, error) {
+		return nil, errors.New("invalid token")
+	}
+	e := newTestEngine()
+	e.SetCaseInsensitiveRouting(true)
+	e.SetCleanPath(true)
+	e.AppendMiddleware(BearerAuthMiddleware(validator, "/Public"))
+	e.GET("/public", func(c Context) {})
+	e.GET("/admin", func(c Context) {})
+
+	cases := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/PUBLIC", http.StatusOK},
+		{http.MethodGet, "/public", http.StatusOK},
+		{http.MethodGet, "//public", http.StatusPermanentRedirect},
+		{http.MethodGet, "/admin", http.StatusUnauthorized},
+		{http.MethodGet, "/ADMIN", http.StatusUnauthorized},
+		{http.MethodGet, "//admin", http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		if w.Code != tc.want {
+			t.Errorf("%s %s: expected %d, got %d", tc.method, tc.path, tc.want, w.Code)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package easyserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+func (e *engine) SetValidator(v func(obj interface{}) error) {
+	e.mustBeRoot("SetValidator")
+	e.validator = v
+}
+
+// 使用Engine设置的校验器校验obj, 未设置校验器时返回nil
+func (c *reqContext) validate(obj interface{}) error {
+	if c.engine == nil || c.engine.validator == nil {
+		return nil
+	}
+	return c.engine.validator(obj)
+}
+
+func (c *reqContext) BindJSON(obj interface{}) error {
+	if c.req.Body == nil {
+		return errors.New("invalid request: body is empty")
+	}
+	if err := json.NewDecoder(c.req.Body).Decode(obj); err != nil {
+		return err
+	}
+	return c.validate(obj)
+}
+
+func (c *reqContext) BindQuery(obj interface{}) error {
+	if err := bindValues(obj, c.req.URL.Query(), "query"); err != nil {
+		return err
+	}
+	return c.validate(obj)
+}
+
+func (c *reqContext) BindForm(obj interface{}) error {
+	if err := c.req.ParseMultipartForm(defaultMultipartMemory); err != nil && err != http.ErrNotMultipart {
+		return err
+	}
+	values := make(map[string][]string, len(c.req.PostForm))
+	for k, v := range c.req.PostForm {
+		values[k] = v
+	}
+	if c.req.MultipartForm != nil {
+		for k, v := range c.req.MultipartForm.Value {
+			values[k] = append(values[k], v...)
+		}
+	}
+	if err := bindValues(obj, values, "form"); err != nil {
+		return err
+	}
+	return c.validate(obj)
+}
+
+func (c *reqContext) BindUri(obj interface{}) error {
+	values := make(map[string][]string, len(c.pathParam))
+	for _, v := range c.pathParam {
+		values[string(v.Key)] = []string{string(v.Value)}
+	}
+	if err := bindValues(obj, values, "uri"); err != nil {
+		return err
+	}
+	return c.validate(obj)
+}
+
+// 将values按字段的tag标签解析到obj指向的结构体中, 标签为"-"的字段被忽略, 未设置标签时使用字段名
+func bindValues(obj interface{}, values map[string][]string, tag string) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("binding element must be a non-nil pointer to a struct")
+	}
+	return bindStruct(v.Elem(), values, tag)
+}
+
+func bindStruct(v reflect.Value, values map[string][]string, tag string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		field := v.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			// 未导出字段
+			continue
+		}
+
+		name := sf.Tag.Get(tag)
+		if name == "-" {
+			continue
+		}
+
+		if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct {
+			if err := bindStruct(field, values, tag); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+		vs, ok := values[name]
+		if !ok || len(vs) == 0 {
+			continue
+		}
+		if err := setField(field, vs); err != nil {
+			return fmt.Errorf("bind field %s from %s %q failed: %v", sf.Name, tag, name, err)
+		}
+	}
+	return nil
+}
+
+func setField(field reflect.Value, vs []string) error {
+	switch field.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(field.Type(), len(vs), len(vs))
+		for i, s := range vs {
+			if err := setValue(slice.Index(i), s); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	case reflect.Array:
+		if len(vs) != field.Len() {
+			return fmt.Errorf("%q is not valid value for %s", vs, field.Type())
+		}
+		for i, s := range vs {
+			if err := setValue(field.Index(i), s); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return setValue(field, vs[0])
+	}
+}
+
+func setValue(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValue(v.Elem(), s)
+	}
+
+	if _, ok := v.Interface().(time.Duration); ok {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		if s == "" {
+			s = "false"
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if s == "" {
+			s = "0"
+		}
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if s == "" {
+			s = "0"
+		}
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if s == "" {
+			s = "0"
+		}
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
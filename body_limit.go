@@ -0,0 +1,31 @@
+package easyserver
+
+import (
+	"net/http"
+	"strings"
+)
+
+// BodyLimitMiddleware 返回限制请求体大小的中间件: Content-Length超过maxBytes时直接响应413且不读取请求体,
+// 否则使用http.MaxBytesReader包装请求体, 读取超过maxBytes的内容时返回错误.
+// 对于携带Expect: 100-continue的请求, http.Server只在handler首次读取请求体时才回复100 Continue,
+// 因此超出限制的请求在客户端发送请求体之前即被拒绝, 且http.Server会在响应后关闭该连接
+func BodyLimitMiddleware(maxBytes int64) func(c Context) {
+	if maxBytes <= 0 {
+		panic("max body size must be positive")
+	}
+	return func(c Context) {
+		req := c.GetReq()
+		if req.ContentLength > maxBytes {
+			if strings.EqualFold(req.Header.Get("Expect"), "100-continue") {
+				c.GetResp().Header().Set("Connection", "close")
+			}
+			c.Abort()
+			http.Error(c.GetResp(), http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if req.Body != nil && req.Body != http.NoBody {
+			req.Body = http.MaxBytesReader(c.GetResp(), req.Body, maxBytes)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,109 @@
+package easyserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// BodyLogConfig BodyLogMiddleware的配置
+type BodyLogConfig struct {
+	// 记录请求体的最大字节数, 请求体超出时只记录大小, 小于等于0时为4096
+	MaxSize int64
+	// 只记录Content-Type为其中之一的请求体, 为空时记录所有请求体
+	ContentTypes []string
+	// 请求体为json时需要脱敏的字段名(不区分大小写), 对应的值替换为"[REDACTED]", 请求体不是合法的json时不脱敏
+	RedactFields []string
+}
+
+// BodyLogMiddleware 返回以Info级别记录请求体的中间件, 读取的内容会重新放回请求体中
+func BodyLogMiddleware(cfg BodyLogConfig) func(c Context) {
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = 4096
+	}
+	contentTypes := make(map[string]struct{}, len(cfg.ContentTypes))
+	for _, v := range cfg.ContentTypes {
+		contentTypes[strings.ToLower(v)] = struct{}{}
+	}
+
+	return func(c Context) {
+		req := c.GetReq()
+		if req.Body == nil || req.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		if _, ok := contentTypes[c.ContentType()]; len(contentTypes) > 0 && !ok {
+			c.Next()
+			return
+		}
+
+		// 最多读取MaxSize+1字节以判断是否超出, 读取的内容与剩余部分重新拼接为请求体
+		b, err := io.ReadAll(io.LimitReader(req.Body, cfg.MaxSize+1))
+		req.Body = readCloser{
+			Reader: io.MultiReader(bytes.NewReader(b), req.Body),
+			Closer: req.Body,
+		}
+		ctx := req.Context()
+		switch {
+		case err != nil:
+			loggerOf(c).Info(ctx, "[EasyServer] request body read failed, err=%v", err)
+		case int64(len(b)) > cfg.MaxSize:
+			loggerOf(c).Info(ctx, "[EasyServer] request body exceeds %d bytes, not logged", cfg.MaxSize)
+		default:
+			loggerOf(c).Info(ctx, "[EasyServer] request body=%s", redactJSONFields(b, cfg.RedactFields))
+		}
+		c.Next()
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func redactJSONFields(body []byte, fields []string) []byte {
+	if len(fields) == 0 {
+		return body
+	}
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return body
+	}
+	v = redactValue(v, fields)
+	b, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return b
+}
+
+func redactValue(v interface{}, fields []string) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if containsFold(fields, k) {
+				t[k] = redacted
+				continue
+			}
+			t[k] = redactValue(val, fields)
+		}
+	case []interface{}:
+		for i, val := range t {
+			t[i] = redactValue(val, fields)
+		}
+	}
+	return v
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,286 @@
+package easyserver
+
+import (
+	"bufio"
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheStore 响应缓存的存储
+type CacheStore interface {
+	// 返回key对应的缓存, 不存在或已过期时返回false
+	Get(key string) ([]byte, bool)
+	// 保存key对应的缓存, ttl后过期
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// CacheConfig CacheMiddleware的配置, 字段为零值时使用默认值
+type CacheConfig struct {
+	TTL time.Duration // 缓存的有效期, 默认为1分钟
+	// 返回请求对应的缓存key, 返回空串时不缓存该请求. 默认为请求URI(GET与HEAD请求共用缓存), 且不缓存带有Authorization或Cookie请求头的请求;
+	// 自定义KeyFunc需在key中包含用户身份, 以免将一个用户的响应返回给其他用户
+	KeyFunc func(req *http.Request) string
+	// 可缓存的响应状态码, 默认为200
+	StatusCodes []int
+}
+
+// CacheMiddleware 返回缓存GET请求响应的中间件, HEAD请求可命中GET请求的缓存(KeyFunc需对二者返回相同的key), 缓存的内容包括状态码、响应头及响应体.
+// 带有Set-Cookie响应头或Cache-Control为private、no-store的响应不缓存. 命中缓存时不再执行后续的中间件及handler, 直接写入缓存的响应
+func CacheMiddleware(store CacheStore, cfg CacheConfig) func(c Context) {
+	if store == nil {
+		panic("cache store must not be nil")
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = time.Minute
+	}
+	if cfg.KeyFunc == nil {
+		// 只缓存GET及HEAD请求, key中不含method以使HEAD请求可以命中GET请求的缓存
+		cfg.KeyFunc = func(req *http.Request) string {
+			if req.Header.Get("Authorization") != "" || req.Header.Get("Cookie") != "" {
+				return ""
+			}
+			return req.URL.RequestURI()
+		}
+	}
+	statusCodes := map[int]struct{}{http.StatusOK: {}}
+	if len(cfg.StatusCodes) > 0 {
+		statusCodes = make(map[int]struct{}, len(cfg.StatusCodes))
+		for _, v := range cfg.StatusCodes {
+			statusCodes[v] = struct{}{}
+		}
+	}
+
+	return func(c Context) {
+		req := c.GetReq()
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+		key := cfg.KeyFunc(req)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if b, ok := store.Get(key); ok {
+			var cached cachedResponse
+			if err := json.Unmarshal(b, &cached); err == nil {
+				c.Abort()
+				cached.writeTo(c.GetResp(), req.Method == http.MethodHead)
+				return
+			}
+		}
+
+		// HEAD请求的响应不含响应体, 不写入缓存
+		if req.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		resp := c.GetResp()
+		w := &cacheWriter{ResponseWriter: resp}
+		c.SetResp(w)
+		defer c.SetResp(resp)
+		c.Next()
+
+		if w.header == nil || w.uncacheable {
+			return
+		}
+		if _, ok := statusCodes[w.status]; !ok {
+			return
+		}
+		b, err := json.Marshal(cachedResponse{Status: w.status, Header: w.header, Body: w.body})
+		if err != nil {
+			return
+		}
+		store.Set(key, b, cfg.TTL)
+	}
+}
+
+type cachedResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+func (r *cachedResponse) writeTo(w http.ResponseWriter, isHead bool) {
+	header := w.Header()
+	// 已设置的响应头(如log id)保持不变
+	for k, v := range r.Header {
+		if _, ok := header[k]; !ok {
+			header[k] = v
+		}
+	}
+	w.WriteHeader(r.Status)
+	if !isHead {
+		_, _ = w.Write(r.Body)
+	}
+}
+
+// cacheWriter 直接写入响应, 同时记录写入的状态码、响应头及响应体
+type cacheWriter struct {
+	http.ResponseWriter
+	status int
+	header http.Header
+	body   []byte
+	// 使用了Flush或Hijack及不允许共享缓存的响应不缓存
+	uncacheable bool
+}
+
+func (w *cacheWriter) WriteHeader(code int) {
+	if w.header == nil && (code < 100 || code > 199) {
+		w.status = code
+		w.header = w.ResponseWriter.Header().Clone()
+		if !sharedCacheable(w.header) {
+			w.uncacheable = true
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// sharedCacheable 返回响应头为header的响应能否缓存并返回给其他请求
+func sharedCacheable(header http.Header) bool {
+	if len(header.Values("Set-Cookie")) > 0 {
+		return false
+	}
+	for _, v := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(v, ",") {
+			directive = strings.ToLower(strings.TrimSpace(directive))
+			if i := strings.IndexByte(directive, '='); i >= 0 {
+				directive = directive[:i]
+			}
+			if directive == "private" || directive == "no-store" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (w *cacheWriter) Write(b []byte) (int, error) {
+	if w.header == nil {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.body = append(w.body, b[:n]...)
+	return n, err
+}
+
+func (w *cacheWriter) Flush() {
+	w.uncacheable = true
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *cacheWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the ResponseWriter doesn't support the Hijacker interface")
+	}
+	w.uncacheable = true
+	return h.Hijack()
+}
+
+func (w *cacheWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// MemoryCacheStore 基于内存的LRU CacheStore, 缓存数量超过容量时淘汰最久未使用的缓存
+type MemoryCacheStore struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type memoryCacheEntry struct {
+	key      string
+	value    []byte
+	expireAt time.Time
+}
+
+// NewMemoryCacheStore 返回容量为capacity的MemoryCacheStore, capacity小于等于0时为1024
+func NewMemoryCacheStore(capacity int) *MemoryCacheStore {
+	if capacity <= 0 {
+		capacity = 1024
+	}
+	return &MemoryCacheStore{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (s *MemoryCacheStore) Get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*memoryCacheEntry)
+	if time.Now().After(entry.expireAt) {
+		s.ll.Remove(e)
+		delete(s.items, key)
+		return nil, false
+	}
+	s.ll.MoveToFront(e)
+	return entry.value, true
+}
+
+func (s *MemoryCacheStore) Set(key string, value []byte, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := &memoryCacheEntry{key: key, value: value, expireAt: time.Now().Add(ttl)}
+	if e, ok := s.items[key]; ok {
+		e.Value = entry
+		s.ll.MoveToFront(e)
+		return
+	}
+	s.items[key] = s.ll.PushFront(entry)
+	for s.ll.Len() > s.capacity {
+		e := s.ll.Back()
+		s.ll.Remove(e)
+		delete(s.items, e.Value.(*memoryCacheEntry).key)
+	}
+}
+
+// RedisCacheClient RedisCacheStore依赖的redis客户端, 可通过简单包装go-redis等客户端实现
+type RedisCacheClient interface {
+	// 返回key对应的值, key不存在时返回错误
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// RedisCacheStore 基于redis的CacheStore, 读写redis失败时视为未命中缓存
+type RedisCacheStore struct {
+	client RedisCacheClient
+	prefix string
+}
+
+// NewRedisCacheStore 返回使用client读写缓存的RedisCacheStore, 缓存的redis key为prefix+key
+func NewRedisCacheStore(client RedisCacheClient, prefix string) *RedisCacheStore {
+	if client == nil {
+		panic("redis client must not be nil")
+	}
+	return &RedisCacheStore{client: client, prefix: prefix}
+}
+
+func (s *RedisCacheStore) Get(key string) ([]byte, bool) {
+	b, err := s.client.Get(context.Background(), s.prefix+key)
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+func (s *RedisCacheStore) Set(key string, value []byte, ttl time.Duration) {
+	_ = s.client.Set(context.Background(), s.prefix+key, value, ttl)
+}
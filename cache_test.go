@@ -0,0 +1,69 @@
+package easyserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// 不同用户的请求及不允许共享的响应不应互相命中缓存
+func TestCacheMiddlewareSkipsPrivateResponses(t *testing.T) {
+	e := newTestEngine()
+	e.AppendMiddleware(CacheMiddleware(NewMemoryCacheStore(16), CacheConfig{}))
+	e.GET("/me", func(c Context) {
+		_, _ = c.GetResp().Write([]byte("user=" + c.GetHeader("Authorization")))
+	})
+	e.GET("/session", func(c Context) {
+		u := c.GetReq().URL.Query().Get("u")
+		c.SetCookie(&http.Cookie{Name: "sid", Value: u})
+		_, _ = c.GetResp().Write([]byte("user=" + u))
+	})
+	e.GET("/private", func(c Context) {
+		c.SetHeader("Cache-Control", "max-age=60, Private")
+		_, _ = c.GetResp().Write([]byte("user=" + c.GetHeader("X-User")))
+	})
+	e.GET("/public", func(c Context) {
+		_, _ = c.GetResp().Write([]byte("user=" + c.GetHeader("X-User")))
+	})
+
+	do := func(path string, header http.Header) string {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	cases := []struct {
+		name, path   string
+		alice, bob   http.Header
+		wantAlice    string
+		wantBob      string
+		wantBobCache bool
+	}{
+		{"authorization", "/me", http.Header{"Authorization": {"alice"}}, http.Header{"Authorization": {"bob"}}, "user=alice", "user=bob", false},
+		{"cookie", "/me", http.Header{"Cookie": {"sid=alice"}, "Authorization": {"alice"}}, http.Header{"Cookie": {"sid=bob"}, "Authorization": {"bob"}}, "user=alice", "user=bob", false},
+		{"private", "/private", http.Header{"X-User": {"alice"}}, http.Header{"X-User": {"bob"}}, "user=alice", "user=bob", false},
+		{"public", "/public", http.Header{"X-User": {"alice"}}, http.Header{"X-User": {"bob"}}, "user=alice", "user=alice", true},
+	}
+	for _, tc := range cases {
+		if got := do(tc.path, tc.alice); got != tc.wantAlice {
+			t.Fatalf("%s: first request got %q, want %q", tc.name, got, tc.wantAlice)
+		}
+		if got := do(tc.path, tc.bob); got != tc.wantBob {
+			t.Fatalf("%s: second request got %q, want %q", tc.name, got, tc.wantBob)
+		}
+	}
+
+	// 带有Set-Cookie的响应不缓存
+	if got := do("/session?u=alice", nil); got != "user=alice" {
+		t.Fatalf("set-cookie: first request got %q", got)
+	}
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/session?u=alice", nil))
+	if cookie := w.Header().Get("Set-Cookie"); cookie == "" {
+		t.Fatalf("set-cookie: second response was served from cache without running the handler")
+	}
+}
@@ -0,0 +1,135 @@
+package easyserver
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CircuitBreakerConfig CircuitBreakerMiddleware的配置, 字段为零值时使用默认值
+type CircuitBreakerConfig struct {
+	FailureThreshold int           // Closed状态下连续失败多少次后进入Open状态, 默认为5
+	SuccessThreshold int           // Half-Open状态下连续成功多少次后进入Closed状态, 默认为1
+	Timeout          time.Duration // Open状态持续多久后进入Half-Open状态, 默认为30s
+	// Half-Open状态下同时允许通过的探测请求数, 其余请求响应503, 默认为1
+	HalfOpenMaxRequests int
+	// 根据响应状态码判断请求是否失败, 默认状态码>=500时为失败, 后续中间件或handler发生panic时总是视为失败
+	IsFailure func(statusCode int) bool
+}
+
+const (
+	circuitClosed = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+type circuitBreaker struct {
+	cfg       CircuitBreakerConfig
+	mu        sync.Mutex
+	state     int
+	failures  int
+	successes int
+	openedAt  time.Time
+	// Half-Open状态下正在执行的探测请求数
+	probes int
+	// 每次状态变化时加1, 用于忽略在之前的状态下通过的请求的结果
+	gen int
+}
+
+// CircuitBreakerMiddleware 返回熔断中间件, 每次调用返回的中间件持有独立的熔断状态, 通常作为单个路由的中间件使用.
+// Open状态下直接响应503及Retry-After, 请求是否失败通过Context.StatusCode判断
+func CircuitBreakerMiddleware(cfg CircuitBreakerConfig) func(c Context) {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.SuccessThreshold <= 0 {
+		cfg.SuccessThreshold = 1
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.HalfOpenMaxRequests <= 0 {
+		cfg.HalfOpenMaxRequests = 1
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = func(statusCode int) bool {
+			return statusCode >= http.StatusInternalServerError
+		}
+	}
+	cb := &circuitBreaker{cfg: cfg}
+
+	return func(c Context) {
+		gen, wait, ok := cb.allow(time.Now())
+		if !ok {
+			secs := int64((wait + time.Second - 1) / time.Second)
+			if secs < 1 {
+				secs = 1
+			}
+			c.GetResp().Header().Set("Retry-After", strconv.FormatInt(secs, 10))
+			c.AbortWithStatus(http.StatusServiceUnavailable)
+			return
+		}
+		panicked := true
+		defer func() {
+			cb.record(gen, panicked || cfg.IsFailure(c.StatusCode()), time.Now())
+		}()
+		c.Next()
+		panicked = false
+	}
+}
+
+// allow 返回请求是否可以通过及通过时的状态版本, 不可以通过时同时返回建议的重试等待时间
+func (cb *circuitBreaker) allow(now time.Time) (int, time.Duration, bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.state == circuitOpen {
+		if wait := cb.openedAt.Add(cb.cfg.Timeout).Sub(now); wait > 0 {
+			return 0, wait, false
+		}
+		cb.setState(circuitHalfOpen, now)
+	}
+	if cb.state == circuitHalfOpen {
+		if cb.probes >= cb.cfg.HalfOpenMaxRequests {
+			return 0, 0, false
+		}
+		cb.probes++
+	}
+	return cb.gen, 0, true
+}
+
+// record 记录在状态版本gen下通过的请求的结果, 状态已变化时忽略
+func (cb *circuitBreaker) record(gen int, failed bool, now time.Time) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if gen != cb.gen {
+		return
+	}
+	switch cb.state {
+	case circuitClosed:
+		if !failed {
+			cb.failures = 0
+			return
+		}
+		if cb.failures++; cb.failures >= cb.cfg.FailureThreshold {
+			cb.setState(circuitOpen, now)
+		}
+	case circuitHalfOpen:
+		cb.probes--
+		if failed {
+			cb.setState(circuitOpen, now)
+			return
+		}
+		if cb.successes++; cb.successes >= cb.cfg.SuccessThreshold {
+			cb.setState(circuitClosed, now)
+		}
+	}
+}
+
+func (cb *circuitBreaker) setState(state int, now time.Time) {
+	cb.state, cb.gen = state, cb.gen+1
+	cb.failures, cb.successes, cb.probes = 0, 0, 0
+	if state == circuitOpen {
+		cb.openedAt = now
+	}
+}
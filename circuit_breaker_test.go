@@ -0,0 +1,79 @@
+package easyserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerOpensOnPanic(t *testing.T) {
+	e := newTestEngine()
+	var calls int
+	e.GET("/panic", func(c Context) {
+		calls++
+		panic("boom")
+	}, CircuitBreakerMiddleware(CircuitBreakerConfig{FailureThreshold: 1}))
+
+	for i := 0; i < 3; i++ {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+	}
+	if calls != 1 {
+		t.Fatalf("expected the circuit to open after the first panic, handler ran %d times", calls)
+	}
+}
+
+func TestCircuitBreakerLimitsHalfOpenProbes(t *testing.T) {
+	e := newTestEngine()
+	var fail bool
+	var calls int32
+	release := make(chan struct{})
+	started := make(chan struct{})
+	e.GET("/", func(c Context) {
+		if fail {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		// 只有第一个通过的请求阻塞, 以便在其执行期间发送其他请求
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+			<-release
+		}
+	}, CircuitBreakerMiddleware(CircuitBreakerConfig{FailureThreshold: 1, Timeout: 10 * time.Millisecond}))
+
+	fail = true
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	fail = false
+	time.Sleep(20 * time.Millisecond)
+
+	// 第一个请求作为探测请求执行期间, 其余请求均被拒绝
+	var wg sync.WaitGroup
+	wg.Add(1)
+	probe := httptest.NewRecorder()
+	go func() {
+		defer wg.Done()
+		e.ServeHTTP(probe, httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+	<-started
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("expected 503 while the probe is in flight, got %d", w.Code)
+		}
+	}
+	close(release)
+	wg.Wait()
+	if probe.Code != http.StatusOK {
+		t.Fatalf("expected the probe to succeed, got %d", probe.Code)
+	}
+
+	// 探测成功后恢复为Closed状态
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected the circuit to close after a successful probe, got %d", w.Code)
+	}
+}
@@ -0,0 +1,49 @@
+package easyserver
+
+func (e *engine) Clone() Engine {
+	c := New().(*engine)
+	c.requestLogging = e.requestLogging
+	if e.logSkipPaths != nil {
+		c.logSkipPaths = make(map[string]struct{}, len(e.logSkipPaths))
+		for k := range e.logSkipPaths {
+			c.logSkipPaths[k] = struct{}{}
+		}
+	}
+	c.requestLog = e.requestLog
+	// RedactHeaders为nil时表示使用默认值, 需与空切片区分
+	if e.requestLog.RedactHeaders != nil {
+		c.requestLog.RedactHeaders = append(make([]string, 0, len(e.requestLog.RedactHeaders)), e.requestLog.RedactHeaders...)
+	}
+	c.logger = e.logger
+	c.caseInsensitive = e.caseInsensitive
+	c.cleanPath = e.cleanPath
+	c.redirectFixedPath = e.redirectFixedPath
+	c.logIdHeader = e.logIdHeader
+	c.genLogId = e.genLogId
+	c.correlationIdHeaders = append([]string(nil), e.correlationIdHeaders...)
+	c.middlewares.Store(append([]middleware(nil), e.globalMiddlewares()...))
+	c.baseCtx = e.baseCtx
+	c.validator = e.validator
+	c.duplicatePolicy = e.duplicatePolicy
+	c.errorHandler = e.errorHandler
+	c.htmlTemplate = e.htmlTemplate
+	c.emptyPathRedirect = e.emptyPathRedirect
+	c.indexPath = e.indexPath
+	c.noRoute = e.noRoute
+	c.serverTiming = e.serverTiming
+	if e.routeCache != nil {
+		c.routeCache = newRouteCache(e.routeCache.capacity)
+	}
+	c.shutdownTimeout = e.shutdownTimeout
+	if e.concurrencySem != nil {
+		c.concurrencySem = make(chan struct{}, cap(e.concurrencySem))
+	}
+	c.concurrencyWait = e.concurrencyWait
+	if e.overrideMethods != nil {
+		c.overrideMethods = make(map[string]struct{}, len(e.overrideMethods))
+		for k := range e.overrideMethods {
+			c.overrideMethods[k] = struct{}{}
+		}
+	}
+	return c
+}
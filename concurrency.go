@@ -0,0 +1,42 @@
+package easyserver
+
+import (
+	"net/http"
+	"time"
+)
+
+func (e *engine) SetMaxConcurrent(n int) {
+	e.mustBeRoot("SetMaxConcurrent")
+	if n <= 0 {
+		e.concurrencySem = nil
+		return
+	}
+	e.concurrencySem = make(chan struct{}, n)
+}
+
+func (e *engine) SetMaxConcurrentWait(d time.Duration) {
+	e.mustBeRoot("SetMaxConcurrentWait")
+	e.concurrencyWait = d
+}
+
+// acquireConcurrency 获取sem中的一个名额, 返回是否获取成功
+func (e *engine) acquireConcurrency(sem chan struct{}, req *http.Request) bool {
+	select {
+	case sem <- struct{}{}:
+		return true
+	default:
+	}
+	if e.concurrencyWait <= 0 {
+		return false
+	}
+	timer := time.NewTimer(e.concurrencyWait)
+	defer timer.Stop()
+	select {
+	case sem <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-req.Context().Done():
+		return false
+	}
+}
@@ -0,0 +1,42 @@
+package easyserver
+
+import (
+	"net/http"
+	"net/url"
+)
+
+func (c *reqContext) SetCookie(cookie *http.Cookie) {
+	http.SetCookie(c.resp, cookie)
+}
+
+func (c *reqContext) Cookie(name string) (string, error) {
+	cookie, err := c.req.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return cookie.Value, nil
+}
+
+// SimpleCookie 返回通过SetSimpleCookie设置的cookie经url解码后的值, 解码失败时返回错误
+func (c *reqContext) SimpleCookie(name string) (string, error) {
+	value, err := c.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return url.QueryUnescape(value)
+}
+
+// SetSimpleCookie 设置cookie, value会经过url编码, 需通过SimpleCookie读取
+func (c *reqContext) SetSimpleCookie(name, value string, maxAge int, path string, secure, httpOnly bool) {
+	if path == "" {
+		path = "/"
+	}
+	c.SetCookie(&http.Cookie{
+		Name:     name,
+		Value:    url.QueryEscape(value),
+		MaxAge:   maxAge,
+		Path:     path,
+		Secure:   secure,
+		HttpOnly: httpOnly,
+	})
+}
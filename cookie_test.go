@@ -0,0 +1,34 @@
+package easyserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCookieReturnsRawValue(t *testing.T) {
+	e := newTestEngine()
+	var raw, simple string
+	e.GET("/", func(c Context) {
+		raw, _ = c.Cookie("raw")
+		simple, _ = c.SimpleCookie("simple")
+	})
+	e.GET("/set", func(c Context) {
+		c.SetSimpleCookie("simple", "a b+c", 0, "", false, false)
+	})
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/set", nil))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "raw", Value: "ab+cd/ef=="})
+	for _, v := range w.Result().Cookies() {
+		req.AddCookie(v)
+	}
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	if raw != "ab+cd/ef==" {
+		t.Fatalf("expected Cookie to return the raw value, got %q", raw)
+	}
+	if simple != "a b+c" {
+		t.Fatalf("expected SimpleCookie to decode the SetSimpleCookie value, got %q", simple)
+	}
+}
@@ -0,0 +1,125 @@
+package easyserver
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// CSRFTokenKey CSRFMiddleware通过Context.Set保存token使用的key
+const CSRFTokenKey = "csrf_token"
+
+// CSRFConfig CSRFMiddleware的配置, 字段为零值时使用默认值
+type CSRFConfig struct {
+	CookieName    string   // 保存token的cookie名称, 默认为_csrf
+	HeaderName    string   // 提交token的请求头名称, 默认为X-CSRF-Token
+	FormFieldName string   // 提交token的表单字段名称, 默认为_csrf
+	TokenLength   int      // token的随机字节数, 默认为32
+	ExcludedPaths []string // 不做校验的请求路径, 与路由查找一样按SetCleanPath、SetCaseInsensitiveRouting匹配
+	// 提交token的查找顺序, 元素为CSRFLookupHeader或CSRFLookupForm, 默认为先请求头后表单字段
+	TokenLookup []string
+}
+
+const (
+	CSRFLookupHeader = "header"
+	CSRFLookupForm   = "form"
+)
+
+// CSRFMiddleware 返回基于double submit cookie的CSRF防护中间件: 请求未携带合法的token cookie时生成新的token并写入cookie,
+// 对POST、PUT、PATCH、DELETE请求校验请求头或表单字段中的token与cookie中的是否一致, 不一致时响应403.
+// 可通过Context.CSRFToken获取token嵌入到页面中
+func CSRFMiddleware(cfg CSRFConfig) func(c Context) {
+	if cfg.CookieName == "" {
+		cfg.CookieName = "_csrf"
+	}
+	if cfg.HeaderName == "" {
+		cfg.HeaderName = "X-CSRF-Token"
+	}
+	if cfg.FormFieldName == "" {
+		cfg.FormFieldName = "_csrf"
+	}
+	if cfg.TokenLength <= 0 {
+		cfg.TokenLength = 32
+	}
+	if len(cfg.TokenLookup) == 0 {
+		cfg.TokenLookup = []string{CSRFLookupHeader, CSRFLookupForm}
+	}
+	for _, v := range cfg.TokenLookup {
+		if v != CSRFLookupHeader && v != CSRFLookupForm {
+			panic("invalid csrf token lookup: " + v)
+		}
+	}
+	excluded := newPathSet(cfg.ExcludedPaths)
+
+	return func(c Context) {
+		req := c.GetReq()
+		if excluded.has(c) {
+			c.Next()
+			return
+		}
+
+		token, _ := c.Cookie(cfg.CookieName)
+		if len(token) != hex.EncodedLen(cfg.TokenLength) {
+			b := make([]byte, cfg.TokenLength)
+			if _, err := rand.Read(b); err != nil {
+				_ = c.Error(err)
+				c.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+			newToken := hex.EncodeToString(b)
+			c.SetCookie(&http.Cookie{
+				Name:     cfg.CookieName,
+				Value:    newToken,
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   req.TLS != nil,
+				SameSite: http.SameSiteLaxMode,
+			})
+			// 请求中没有合法的token时不可能通过校验
+			if isStateChanging(req.Method) {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			token = newToken
+		} else if isStateChanging(req.Method) {
+			var sent string
+			for _, v := range cfg.TokenLookup {
+				if v == CSRFLookupHeader {
+					sent = req.Header.Get(cfg.HeaderName)
+				} else {
+					sent = req.FormValue(cfg.FormFieldName)
+				}
+				if sent != "" {
+					break
+				}
+			}
+			if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(sent)), []byte(token)) != 1 {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+		}
+
+		c.Set(CSRFTokenKey, token)
+		c.Next()
+	}
+}
+
+// CSRF 同CSRFMiddleware
+func CSRF(cfg CSRFConfig) func(c Context) {
+	return CSRFMiddleware(cfg)
+}
+
+func isStateChanging(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func (c *reqContext) CSRFToken() string {
+	token, _ := c.keys[CSRFTokenKey].(string)
+	return token
+}
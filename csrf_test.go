@@ -0,0 +1,23 @@
+package easyserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// 排除路径与路由一样按大小写不敏感匹配, 其他路径不能通过改变大小写跳过校验
+func TestCSRFExcludedPathsMatchRoutingPath(t *testing.T) {
+	e := newTestEngine()
+	e.SetCaseInsensitiveRouting(true)
+	e.AppendMiddleware(CSRFMiddleware(CSRFConfig{ExcludedPaths: []string{"/webhook"}}))
+	e.POST("/webhook", func(c Context) {})
+	e.POST("/form", func(c Context) {})
+	for path, want := range map[string]int{"/WEBHOOK": http.StatusOK, "/FORM": http.StatusForbidden} {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		if w.Code != want {
+			t.Errorf("POST %s: expected %d, got %d", path, want, w.Code)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package easyserver
+
+import (
+	"net/http"
+)
+
+func (e *engine) RegisterE(method, path string, handler func(c Context) error, mws ...func(c Context)) {
+	if handler == nil {
+		panic("handler must not be nil")
+	}
+	e.Register(Node{
+		Method:      method,
+		Path:        path,
+		Middlewares: mws,
+		Name:        funcName(handler),
+		Handler: func(c Context) {
+			err := handler(c)
+			// 中间件可能通过SetResp包装了响应(如ETagMiddleware缓存响应体), 需检查c.GetResp()是否已写入
+			if err == nil || responseWritten(c.GetResp()) || c.(*reqContext).writer.Written() {
+				return
+			}
+			if h := e.errorHandlerOf(); h != nil {
+				h(c, err)
+				return
+			}
+			defaultErrorHandler(c, err)
+		},
+	})
+}
+
+func (e *engine) GETE(path string, handler func(c Context) error, mws ...func(c Context)) {
+	e.RegisterE(http.MethodGet, path, handler, mws...)
+}
+
+func (e *engine) POSTE(path string, handler func(c Context) error, mws ...func(c Context)) {
+	e.RegisterE(http.MethodPost, path, handler, mws...)
+}
+
+func (e *engine) SetErrorHandler(h func(c Context, err error)) {
+	e.errorHandler = h
+}
+
+func defaultErrorHandler(c Context, err error) {
+	loggerOf(c).Error(c.GetReq().Context(), "[EasyServer] handler returned error, matchPath=%v, err=%v", c.GetMatchPath(), err)
+	http.Error(c.GetResp(), http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
@@ -0,0 +1,170 @@
+package easyserver
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ETagOptions ETagMiddleware的配置
+type ETagOptions struct {
+	// 为true时生成弱ETag(W/"...")
+	Weak bool
+	// 计算响应体的哈希, 默认为MD5的十六进制编码
+	Hash func(body []byte) string
+	// 缓存响应体的最大字节数, 超过时不生成ETag, 小于等于0时为1MB
+	MaxBufferSize int
+}
+
+// ETag 同ETagMiddleware(ETagOptions{MaxBufferSize: maxBufferSize})
+func ETag(maxBufferSize int) func(c Context) {
+	return ETagMiddleware(ETagOptions{MaxBufferSize: maxBufferSize})
+}
+
+// ETagMiddleware 返回为GET请求的200响应生成ETag并处理If-None-Match的中间件: 缓存响应体并计算其哈希作为ETag,
+// If-None-Match匹配时丢弃响应体并响应304. 响应体超过MaxBufferSize字节或handler调用了Flush时不再缓存, 也不生成ETag
+func ETagMiddleware(opts ETagOptions) func(c Context) {
+	if opts.Hash == nil {
+		opts.Hash = func(body []byte) string {
+			sum := md5.Sum(body)
+			return hex.EncodeToString(sum[:])
+		}
+	}
+	if opts.MaxBufferSize <= 0 {
+		opts.MaxBufferSize = 1 << 20
+	}
+	return func(c Context) {
+		if c.GetReq().Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		resp := c.GetResp()
+		w := &etagWriter{ResponseWriter: resp, maxSize: opts.MaxBufferSize}
+		c.SetResp(w)
+		defer c.SetResp(resp)
+		c.Next()
+
+		if w.passThrough {
+			return
+		}
+		status := w.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		header := resp.Header()
+		if status == http.StatusOK && header.Get("ETag") == "" {
+			etag := `"` + opts.Hash(w.buf.Bytes()) + `"`
+			if opts.Weak {
+				etag = "W/" + etag
+			}
+			header.Set("ETag", etag)
+		}
+		if status == http.StatusOK && etagMatch(c.GetReq().Header.Get("If-None-Match"), header.Get("ETag")) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			resp.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.flushBuffer()
+	}
+}
+
+// etagMatch 返回If-None-Match请求头是否与etag匹配, 使用弱比较
+func etagMatch(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, v := range strings.Split(ifNoneMatch, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// etagWriter 缓存状态码及响应体, 超过maxSize后将已缓存的内容写出并切换为直接写入
+type etagWriter struct {
+	http.ResponseWriter
+	maxSize     int
+	status      int
+	buf         bytes.Buffer
+	passThrough bool
+}
+
+func (w *etagWriter) WriteHeader(code int) {
+	if w.passThrough {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	// 1xx(101除外)为信息性响应, 直接写出
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *etagWriter) Write(b []byte) (int, error) {
+	if w.passThrough {
+		return w.ResponseWriter.Write(b)
+	}
+	if w.buf.Len()+len(b) > w.maxSize {
+		if err := w.flushBuffer(); err != nil {
+			return 0, err
+		}
+		return w.ResponseWriter.Write(b)
+	}
+	return w.buf.Write(b)
+}
+
+// flushBuffer 写出缓存的状态码及响应体并切换为直接写入
+func (w *etagWriter) flushBuffer() error {
+	if w.passThrough {
+		return nil
+	}
+	w.passThrough = true
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+func (w *etagWriter) Flush() {
+	_ = w.flushBuffer()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *etagWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the ResponseWriter doesn't support the Hijacker interface")
+	}
+	w.passThrough = true
+	return h.Hijack()
+}
+
+// Written 返回是否已写入状态码或响应体, 包括已缓存尚未写出的内容
+func (w *etagWriter) Written() bool {
+	return w.passThrough || w.status != 0 || w.buf.Len() > 0
+}
+
+func (w *etagWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,37 @@
+package easyserver
+
+import "net/http"
+
+const faviconPath = "/favicon.ico"
+
+func (e *engine) Favicon(filepath string) {
+	e.Register(Node{
+		Method: http.MethodGet,
+		Path:   faviconPath,
+		Name:   "easyserver.Favicon",
+		Handler: func(c Context) {
+			c.GetResp().Header().Set("Cache-Control", "public, max-age=31536000")
+			c.File(filepath)
+		},
+	})
+}
+
+func (e *engine) FaviconIgnore() {
+	// 请求日志由最上层的父Engine打印, 需使用添加了子路由前缀的完整路径
+	root, path := e, faviconPath
+	for ; root.parent != nil; root = root.parent {
+		path = root.prefix + path
+	}
+	if root.logSkipPaths == nil {
+		root.logSkipPaths = make(map[string]struct{})
+	}
+	root.logSkipPaths[path] = struct{}{}
+	e.Register(Node{
+		Method: http.MethodGet,
+		Path:   faviconPath,
+		Name:   "easyserver.FaviconIgnore",
+		Handler: func(c Context) {
+			c.GetResp().WriteHeader(http.StatusNoContent)
+		},
+	})
+}
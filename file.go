@@ -0,0 +1,38 @@
+package easyserver
+
+import (
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+func (c *reqContext) File(filepath string) {
+	if !fileExists(filepath) {
+		http.NotFound(c.resp, c.req)
+		return
+	}
+	http.ServeFile(c.resp, c.req, filepath)
+}
+
+func (c *reqContext) FileAttachment(path, filename string) {
+	if !fileExists(path) {
+		http.NotFound(c.resp, c.req)
+		return
+	}
+	if filename == "" {
+		filename = filepath.Base(path)
+	}
+	c.resp.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": filename,
+	}))
+	c.File(path)
+}
+
+func fileExists(path string) bool {
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
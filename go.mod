@@ -6,4 +6,10 @@ require (
 	github.com/gogokit/logs v0.0.0-20220205070630-f29a08415be1
 	github.com/gogokit/router v0.0.0-20220205070459-84cc9e1c0f2a
 	github.com/gogokit/tostr v1.0.3
+	github.com/prometheus/client_golang v1.11.1
+	go.opentelemetry.io/otel v1.3.0
+	go.opentelemetry.io/otel/trace v1.3.0
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
+	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c
+	golang.org/x/term v0.0.0-20201126162022-7de9c90e9dd1
 )
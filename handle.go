@@ -0,0 +1,43 @@
+package easyserver
+
+import "net/http"
+
+func (e *engine) Handle(method, path string, handler http.HandlerFunc, mws ...func(c Context)) {
+	e.Register(Node{
+		Method:      method,
+		Path:        path,
+		Middlewares: mws,
+		Handler:     WrapF(handler),
+		Name:        httpHandlerName(handler),
+	})
+}
+
+func (e *engine) GET(path string, handler func(c Context), mws ...func(c Context)) {
+	e.RegisterWithMiddlewares(http.MethodGet, path, handler, mws...)
+}
+
+func (e *engine) POST(path string, handler func(c Context), mws ...func(c Context)) {
+	e.RegisterWithMiddlewares(http.MethodPost, path, handler, mws...)
+}
+
+func (e *engine) PUT(path string, handler func(c Context), mws ...func(c Context)) {
+	e.RegisterWithMiddlewares(http.MethodPut, path, handler, mws...)
+}
+
+func (e *engine) PATCH(path string, handler func(c Context), mws ...func(c Context)) {
+	e.RegisterWithMiddlewares(http.MethodPatch, path, handler, mws...)
+}
+
+func (e *engine) DELETE(path string, handler func(c Context), mws ...func(c Context)) {
+	e.RegisterWithMiddlewares(http.MethodDelete, path, handler, mws...)
+}
+
+func (e *engine) Any(path string, handler func(c Context), mws ...func(c Context)) {
+	e.Match(anyMethods, path, handler, mws...)
+}
+
+func (e *engine) Match(methods []string, path string, handler func(c Context), mws ...func(c Context)) {
+	for _, method := range methods {
+		e.RegisterWithMiddlewares(method, path, handler, mws...)
+	}
+}
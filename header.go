@@ -0,0 +1,31 @@
+package easyserver
+
+import (
+	"mime"
+	"strings"
+)
+
+func (c *reqContext) GetHeader(key string) string {
+	return c.req.Header.Get(key)
+}
+
+func (c *reqContext) SetHeader(key, value string) {
+	c.resp.Header().Set(key, value)
+}
+
+func (c *reqContext) AddHeader(key, value string) {
+	c.resp.Header().Add(key, value)
+}
+
+func (c *reqContext) ContentType() string {
+	ct := c.req.Header.Get("Content-Type")
+	if ct == "" {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		// 格式不合法时尽量取出分号之前的部分
+		return strings.ToLower(strings.TrimSpace(strings.SplitN(ct, ";", 2)[0]))
+	}
+	return mediaType
+}
@@ -0,0 +1,36 @@
+package easyserver
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+func (e *engine) Health(path string, checks ...func() error) {
+	e.Register(Node{
+		Method: http.MethodGet,
+		Path:   path,
+		Name:   "easyserver.Health",
+		Handler: func(c Context) {
+			var failed []string
+			for i, check := range checks {
+				if check == nil {
+					continue
+				}
+				if err := check(); err != nil {
+					failed = append(failed, fmt.Sprintf("check %d failed: %v", i, err))
+				}
+			}
+
+			resp := c.GetResp()
+			resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			resp.Header().Set("Cache-Control", "no-cache")
+			if len(failed) > 0 {
+				resp.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = resp.Write([]byte(strings.Join(failed, "\n")))
+				return
+			}
+			_, _ = resp.Write([]byte("ok"))
+		},
+	})
+}
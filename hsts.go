@@ -0,0 +1,28 @@
+package easyserver
+
+import (
+	"strconv"
+	"time"
+)
+
+// HSTSMiddleware 返回对https请求(req.TLS不为nil)设置Strict-Transport-Security响应头的中间件
+func HSTSMiddleware(maxAge time.Duration, includeSubDomains, preload bool) func(c Context) {
+	value := hstsValue(maxAge, includeSubDomains, preload)
+	return func(c Context) {
+		if c.GetReq().TLS != nil {
+			c.GetResp().Header().Set("Strict-Transport-Security", value)
+		}
+		c.Next()
+	}
+}
+
+func hstsValue(maxAge time.Duration, includeSubDomains, preload bool) string {
+	value := "max-age=" + strconv.FormatInt(int64(maxAge/time.Second), 10)
+	if includeSubDomains {
+		value += "; includeSubDomains"
+	}
+	if preload {
+		value += "; preload"
+	}
+	return value
+}
@@ -0,0 +1,31 @@
+package easyserver
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+)
+
+const MIMEHTML = "text/html"
+
+func (e *engine) LoadHTMLGlob(pattern string) {
+	e.mustBeRoot("LoadHTMLGlob")
+	e.htmlTemplate = template.Must(template.ParseGlob(pattern))
+}
+
+func (e *engine) LoadHTMLFiles(files ...string) {
+	e.mustBeRoot("LoadHTMLFiles")
+	e.htmlTemplate = template.Must(template.ParseFiles(files...))
+}
+
+func (c *reqContext) HTML(code int, name string, data interface{}) error {
+	if c.engine == nil || c.engine.htmlTemplate == nil {
+		return errors.New("html templates not loaded, call LoadHTMLGlob or LoadHTMLFiles first")
+	}
+	// 先渲染到buffer, 避免执行失败时写出不完整的页面
+	var buf bytes.Buffer
+	if err := c.engine.htmlTemplate.ExecuteTemplate(&buf, name, data); err != nil {
+		return err
+	}
+	return c.render(code, MIMEHTML+"; charset=utf-8", buf.Bytes())
+}
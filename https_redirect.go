@@ -0,0 +1,42 @@
+package easyserver
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// 返回请求是否通过https发起, 经过代理时根据X-Forwarded-Proto判断
+func isHTTPS(req *http.Request) bool {
+	return req.TLS != nil || strings.EqualFold(req.Header.Get("X-Forwarded-Proto"), "https")
+}
+
+// HTTPSRedirectMiddleware 返回将http请求301重定向到httpsPort端口的https地址的中间件, httpsPort为443时重定向地址中不含端口
+func HTTPSRedirectMiddleware(httpsPort int) func(c Context) {
+	return func(c Context) {
+		req := c.GetReq()
+		if isHTTPS(req) {
+			c.Next()
+			return
+		}
+
+		host := req.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if strings.IndexByte(host, ':') >= 0 {
+			// IPv6地址
+			host = "[" + host + "]"
+		}
+		if httpsPort != 443 && httpsPort > 0 {
+			host += ":" + strconv.Itoa(httpsPort)
+		}
+
+		u := *req.URL
+		u.Scheme = "https"
+		u.Host = host
+		http.Redirect(c.GetResp(), req, u.String(), http.StatusMovedPermanently)
+		c.Abort()
+	}
+}
@@ -0,0 +1,114 @@
+package easyserver
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// IPFilterConfig IPFilterMiddleware的配置
+type IPFilterConfig struct {
+	AllowCIDRs []string // 为空时允许所有不在DenyCIDRs中的IP
+	DenyCIDRs  []string
+	// 为true时从X-Forwarded-For、X-Real-IP中获取客户端IP, 否则只使用RemoteAddr
+	TrustProxyHeaders bool
+	// 可信代理的CIDR, TrustProxyHeaders为true时生效. 为空时取X-Forwarded-For的最后一个地址(即直接相连的代理记录的地址),
+	// 不为空时RemoteAddr不是可信代理则只使用RemoteAddr, 否则从右向左跳过X-Forwarded-For中的可信代理, 取第一个不可信的地址.
+	// X-Forwarded-For左侧的地址可被客户端伪造, 因此不会被使用
+	TrustedProxies []string
+}
+
+// IPFilterMiddleware 返回按客户端IP过滤请求的中间件, 先检查DenyCIDRs再检查AllowCIDRs, 拒绝的请求响应403.
+// CIDR不合法时panic
+func IPFilterMiddleware(cfg IPFilterConfig) func(c Context) {
+	allow, deny := mustParseCIDRs(cfg.AllowCIDRs), mustParseCIDRs(cfg.DenyCIDRs)
+	trusted := mustParseCIDRs(cfg.TrustedProxies)
+	return func(c Context) {
+		var ip net.IP
+		if cfg.TrustProxyHeaders {
+			ip = forwardedClientIP(c.GetReq(), trusted)
+		} else {
+			ip = net.ParseIP(remoteIP(c.GetReq()))
+		}
+		if ip == nil || containsIP(deny, ip) || (len(allow) > 0 && !containsIP(allow, ip)) {
+			c.Abort()
+			http.Error(c.GetResp(), "403 forbidden: ip not allowed", http.StatusForbidden)
+			return
+		}
+		c.Next()
+	}
+}
+
+func mustParseCIDRs(cidrs []string) []*net.IPNet {
+	ret := make([]*net.IPNet, 0, len(cidrs))
+	for _, v := range cidrs {
+		_, n, err := net.ParseCIDR(v)
+		if err != nil {
+			panic(fmt.Sprintf("invalid CIDR %q: %v", v, err))
+		}
+		ret = append(ret, n)
+	}
+	return ret
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func remoteIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(req.RemoteAddr))
+	if err != nil {
+		return strings.TrimSpace(req.RemoteAddr)
+	}
+	return host
+}
+
+// forwardedClientIP 按IPFilterConfig.TrustedProxies的说明获取客户端IP, X-Forwarded-For中含有不合法的地址时返回nil
+func forwardedClientIP(req *http.Request, trusted []*net.IPNet) net.IP {
+	remote := net.ParseIP(remoteIP(req))
+	if len(trusted) > 0 && (remote == nil || !containsIP(trusted, remote)) {
+		return remote
+	}
+	var hops []string
+	for _, v := range req.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+	var ip net.IP
+	for i := len(hops) - 1; i >= 0; i-- {
+		if ip = net.ParseIP(strings.TrimSpace(hops[i])); ip == nil {
+			return nil
+		}
+		if !containsIP(trusted, ip) {
+			return ip
+		}
+	}
+	// X-Forwarded-For中全部为可信代理时取最左侧的地址
+	if ip != nil {
+		return ip
+	}
+	if v := strings.TrimSpace(req.Header.Get("X-Real-IP")); v != "" {
+		return net.ParseIP(v)
+	}
+	return remote
+}
+
+func (c *reqContext) ClientIP() string {
+	if v := c.req.Header.Get("X-Forwarded-For"); v != "" {
+		if i := strings.IndexByte(v, ','); i >= 0 {
+			v = v[:i]
+		}
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	if v := strings.TrimSpace(c.req.Header.Get("X-Real-IP")); v != "" {
+		return v
+	}
+	return remoteIP(c.req)
+}
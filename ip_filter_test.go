@@ -0,0 +1,45 @@
+package easyserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIPFilterIgnoresSpoofedForwardedFor(t *testing.T) {
+	cases := []struct {
+		name    string
+		trusted []string
+		remote  string
+		xff     []string
+		want    int
+	}{
+		{"spoofed leftmost entry", nil, "192.0.2.1:1234", []string{"10.9.9.9, 203.0.113.7"}, http.StatusForbidden},
+		{"rightmost entry allowed", nil, "192.0.2.1:1234", []string{"203.0.113.7, 10.9.9.9"}, http.StatusOK},
+		{"split headers", nil, "192.0.2.1:1234", []string{"10.9.9.9", "203.0.113.7"}, http.StatusForbidden},
+		{"skip trusted hops", []string{"192.0.2.0/24"}, "192.0.2.1:1234", []string{"10.9.9.9, 10.1.1.1, 192.0.2.5"}, http.StatusOK},
+		{"spoofed behind trusted hops", []string{"192.0.2.0/24"}, "192.0.2.1:1234", []string{"10.9.9.9, 203.0.113.7, 192.0.2.5"}, http.StatusForbidden},
+		{"untrusted remote", []string{"192.0.2.0/24"}, "198.51.100.1:1234", []string{"10.9.9.9"}, http.StatusForbidden},
+		{"malformed entry", nil, "10.0.0.1:1234", []string{"10.9.9.9, bogus"}, http.StatusForbidden},
+		{"no header", nil, "10.0.0.1:1234", nil, http.StatusOK},
+	}
+	for _, tc := range cases {
+		e := newTestEngine()
+		e.AppendMiddleware(IPFilterMiddleware(IPFilterConfig{
+			AllowCIDRs:        []string{"10.0.0.0/8"},
+			TrustProxyHeaders: true,
+			TrustedProxies:    tc.trusted,
+		}))
+		e.GET("/", func(c Context) {})
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tc.remote
+		for _, v := range tc.xff {
+			req.Header.Add("X-Forwarded-For", v)
+		}
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%s: expected %d, got %d", tc.name, tc.want, w.Code)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package easyserver
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	JWTHS256 = "HS256"
+	JWTRS256 = "RS256"
+)
+
+// JWTConfig JWTAuth的配置
+type JWTConfig struct {
+	// 签名算法, JWTHS256或JWTRS256, 默认为JWTHS256
+	SigningMethod string
+	// 校验签名的key, HS256时为[]byte, RS256时为*rsa.PublicKey
+	Key interface{}
+	// 不为nil时忽略Key, 根据token的header(如kid)返回校验签名的key
+	KeyFunc func(header map[string]interface{}) (interface{}, error)
+	// 请求没有Authorization请求头时从名称为CookieName的cookie中获取token, 为空时不从cookie获取
+	CookieName string
+	// 校验exp、nbf、iat时允许的时钟偏差
+	Leeway time.Duration
+	// 签名及时间校验通过后对claims的额外校验, 可为nil
+	Validator func(claims map[string]interface{}) error
+}
+
+// JWTAuth 返回JWT鉴权中间件: 从Authorization: Bearer请求头或cookie中获取token, 校验签名及exp、nbf、iat,
+// 成功时将claims(map[string]interface{})通过Context.Set(ClaimsKey, claims)保存, 失败时响应401及json格式的错误信息
+func JWTAuth(cfg JWTConfig) func(c Context) {
+	if cfg.SigningMethod == "" {
+		cfg.SigningMethod = JWTHS256
+	}
+	if cfg.SigningMethod != JWTHS256 && cfg.SigningMethod != JWTRS256 {
+		panic("unsupported jwt signing method: " + cfg.SigningMethod)
+	}
+	if cfg.Key == nil && cfg.KeyFunc == nil {
+		panic("jwt key or key func must be provided")
+	}
+
+	return func(c Context) {
+		token := jwtFromRequest(c.GetReq(), cfg.CookieName)
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, map[string]string{"error": "missing token"})
+			return
+		}
+		claims, err := parseJWT(token, &cfg, time.Now())
+		if err == nil && cfg.Validator != nil {
+			err = cfg.Validator(claims)
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+			return
+		}
+		c.Set(ClaimsKey, claims)
+		c.Next()
+	}
+}
+
+func jwtFromRequest(req *http.Request, cookieName string) string {
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		const prefix = "Bearer "
+		if len(auth) > len(prefix) && strings.EqualFold(auth[:len(prefix)], prefix) {
+			return strings.TrimSpace(auth[len(prefix):])
+		}
+		return ""
+	}
+	if cookieName != "" {
+		if ck, err := req.Cookie(cookieName); err == nil {
+			return ck.Value
+		}
+	}
+	return ""
+}
+
+func parseJWT(token string, cfg *JWTConfig, now time.Time) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header map[string]interface{}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, errors.New("malformed token header")
+	}
+	if alg, _ := header["alg"].(string); alg != cfg.SigningMethod {
+		return nil, fmt.Errorf("unexpected signing method %v", header["alg"])
+	}
+
+	key := cfg.Key
+	if cfg.KeyFunc != nil {
+		var err error
+		if key, err = cfg.KeyFunc(header); err != nil {
+			return nil, err
+		}
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("malformed token signature")
+	}
+	if err := verifyJWT(cfg.SigningMethod, key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, errors.New("malformed token claims")
+	}
+	if err := checkJWTTime(claims, now, cfg.Leeway); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func verifyJWT(method string, key interface{}, signingInput string, sig []byte) error {
+	switch method {
+	case JWTHS256:
+		k, ok := key.([]byte)
+		if !ok {
+			return errors.New("invalid key type for HS256")
+		}
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return errors.New("signature is invalid")
+		}
+	case JWTRS256:
+		k, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("invalid key type for RS256")
+		}
+		sum := sha256.Sum256([]byte(signingInput))
+		if rsa.VerifyPKCS1v15(k, crypto.SHA256, sum[:], sig) != nil {
+			return errors.New("signature is invalid")
+		}
+	}
+	return nil
+}
+
+func checkJWTTime(claims map[string]interface{}, now time.Time, leeway time.Duration) error {
+	unix := func(name string) (time.Time, bool, error) {
+		v, ok := claims[name]
+		if !ok {
+			return time.Time{}, false, nil
+		}
+		n, ok := v.(json.Number)
+		if !ok {
+			return time.Time{}, false, fmt.Errorf("invalid %s claim", name)
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid %s claim", name)
+		}
+		return time.Unix(int64(f), 0), true, nil
+	}
+
+	if exp, ok, err := unix("exp"); err != nil {
+		return err
+	} else if ok && !now.Before(exp.Add(leeway)) {
+		return errors.New("token is expired")
+	}
+	if nbf, ok, err := unix("nbf"); err != nil {
+		return err
+	} else if ok && now.Add(leeway).Before(nbf) {
+		return errors.New("token is not valid yet")
+	}
+	if iat, ok, err := unix("iat"); err != nil {
+		return err
+	} else if ok && now.Add(leeway).Before(iat) {
+		return errors.New("token used before issued")
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package easyserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func (e *engine) OnStartup(fn func()) {
+	e.mustBeRoot("OnStartup")
+	if fn == nil {
+		panic("startup hook must not be nil")
+	}
+	e.lifecycleMu.Lock()
+	e.startupHooks = append(e.startupHooks, fn)
+	e.lifecycleMu.Unlock()
+}
+
+func (e *engine) OnShutdown(fn func(ctx context.Context) error) {
+	e.mustBeRoot("OnShutdown")
+	if fn == nil {
+		panic("shutdown hook must not be nil")
+	}
+	e.lifecycleMu.Lock()
+	e.shutdownHooks = append(e.shutdownHooks, fn)
+	e.lifecycleMu.Unlock()
+}
+
+// serve 在addr上启动服务, certFile和keyFile不为空时使用https
+func (e *engine) serve(addr, certFile, keyFile string) error {
+	srv, ln, err := e.listen(addr)
+	if err != nil {
+		return err
+	}
+	return serveOn(srv, ln, certFile, keyFile)
+}
+
+// listen 监听addr并登记对应的http.Server, 使之后调用的Shutdown能够关闭该服务, 然后执行启动钩子
+func (e *engine) listen(addr string) (*http.Server, net.Listener, error) {
+	srv := &http.Server{Addr: addr, Handler: e}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e.lifecycleMu.Lock()
+	e.servers = append(e.servers, srv)
+	hooks := append([]func(){}, e.startupHooks...)
+	e.lifecycleMu.Unlock()
+	for _, fn := range hooks {
+		fn()
+	}
+	return srv, ln, nil
+}
+
+// serveOn 在ln上运行srv, 在此之前已被Shutdown的srv直接返回http.ErrServerClosed
+func serveOn(srv *http.Server, ln net.Listener, certFile, keyFile string) error {
+	if certFile != "" || keyFile != "" {
+		return srv.ServeTLS(ln, certFile, keyFile)
+	}
+	return srv.Serve(ln)
+}
+
+func (e *engine) Shutdown(ctx context.Context) error {
+	e.mustBeRoot("Shutdown")
+	e.lifecycleMu.Lock()
+	servers := e.servers
+	e.servers = nil
+	hooks := append([]func(ctx context.Context) error{}, e.shutdownHooks...)
+	e.lifecycleMu.Unlock()
+
+	var errs shutdownErrors
+	for _, srv := range servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, fn := range hooks {
+		if err := fn(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (e *engine) SetShutdownTimeout(d time.Duration) {
+	e.mustBeRoot("SetShutdownTimeout")
+	e.shutdownTimeout = d
+}
+
+func (e *engine) RunHttpUntilSignal(port int) error {
+	return e.RunUntilSignal(port, "", "")
+}
+
+func (e *engine) RunUntilSignal(port int, certFile, keyFile string) error {
+	e.mustBeRoot("RunUntilSignal")
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sig)
+
+	// 在等待信号前同步登记服务, 避免服务登记前收到的信号使Shutdown无法关闭该服务
+	srv, ln, err := e.listen(":" + fmt.Sprintf("%d", port))
+	if err != nil {
+		return err
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- serveOn(srv, ln, certFile, keyFile)
+	}()
+
+	select {
+	case err := <-serveErr:
+		// 其他goroutine调用了Shutdown
+		if err == http.ErrServerClosed {
+			return nil
+		}
+		return err
+	case <-sig:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.shutdownTimeout)
+	defer cancel()
+	err = e.Shutdown(ctx)
+	if sErr := <-serveErr; err == nil && sErr != http.ErrServerClosed {
+		err = sErr
+	}
+	return err
+}
+
+// shutdownErrors Shutdown过程中产生的多个错误
+type shutdownErrors []error
+
+func (errs shutdownErrors) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return "shutdown: " + strings.Join(msgs, "; ")
+}
@@ -0,0 +1,60 @@
+package easyserver
+
+import (
+	"context"
+
+	"github.com/gogokit/logs"
+)
+
+// Logger Engine输出日志使用的接口, 日志级别与github.com/gogokit/logs一致, msg为格式化字符串
+type Logger interface {
+	Trace(ctx context.Context, msg string, args ...interface{})
+	Debug(ctx context.Context, msg string, args ...interface{})
+	Info(ctx context.Context, msg string, args ...interface{})
+	Warn(ctx context.Context, msg string, args ...interface{})
+	Error(ctx context.Context, msg string, args ...interface{})
+	Critical(ctx context.Context, msg string, args ...interface{})
+}
+
+// LogsAdapter 基于github.com/gogokit/logs实现的Logger, 为Engine的默认Logger
+type LogsAdapter struct{}
+
+func (LogsAdapter) Trace(ctx context.Context, msg string, args ...interface{}) {
+	logs.CtxTrace(ctx, msg, args...)
+}
+
+func (LogsAdapter) Debug(ctx context.Context, msg string, args ...interface{}) {
+	logs.CtxDebug(ctx, msg, args...)
+}
+
+func (LogsAdapter) Info(ctx context.Context, msg string, args ...interface{}) {
+	logs.CtxInfo(ctx, msg, args...)
+}
+
+func (LogsAdapter) Warn(ctx context.Context, msg string, args ...interface{}) {
+	logs.CtxWarn(ctx, msg, args...)
+}
+
+func (LogsAdapter) Error(ctx context.Context, msg string, args ...interface{}) {
+	logs.CtxError(ctx, msg, args...)
+}
+
+func (LogsAdapter) Critical(ctx context.Context, msg string, args ...interface{}) {
+	logs.CtxCritical(ctx, msg, args...)
+}
+
+func (e *engine) SetLogger(l Logger) {
+	e.mustBeRoot("SetLogger")
+	if l == nil {
+		l = LogsAdapter{}
+	}
+	e.logger = l
+}
+
+// 返回c所属Engine的Logger, c不是由Engine创建时返回LogsAdapter
+func loggerOf(c Context) Logger {
+	if rc, ok := c.(*reqContext); ok && rc.engine != nil {
+		return rc.engine.logger
+	}
+	return LogsAdapter{}
+}
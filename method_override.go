@@ -0,0 +1,66 @@
+package easyserver
+
+import (
+	"net/http"
+	"strings"
+)
+
+// MethodOverrideMiddleware 返回对POST请求根据X-HTTP-Method-Override请求头或_method表单字段改写req.Method的中间件,
+// allowed为允许改写成的方法, 为空时允许PUT、PATCH、DELETE. 改写发生在路由查找之前, 因此需作为全局中间件追加,
+// 改写后路由及405判断均以新的方法为准, 原方法不再保留.
+// 读取_method时会解析urlencoded表单并读取整个请求体, 之后表单仍可通过BindForm等获取, 但GetRawData无法再读取原始请求体;
+// 需追加在BodyLimitMiddleware之后以限制读取的大小, 解析表单失败时响应400
+func MethodOverrideMiddleware(allowed ...string) func(c Context) {
+	if len(allowed) == 0 {
+		allowed = defaultOverrideMethods
+	}
+	set := make(map[string]struct{}, len(allowed))
+	for _, v := range allowed {
+		set[strings.ToUpper(v)] = struct{}{}
+	}
+	return func(c Context) {
+		if err := overrideMethod(c.GetReq(), set, true); err != nil {
+			c.Abort()
+			http.Error(c.GetResp(), "400 bad request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		c.Next()
+	}
+}
+
+var defaultOverrideMethods = []string{http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+func (e *engine) SetMethodOverride(enable bool) {
+	e.mustBeRoot("SetMethodOverride")
+	if !enable {
+		e.overrideMethods = nil
+		return
+	}
+	e.overrideMethods = make(map[string]struct{}, len(defaultOverrideMethods))
+	for _, v := range defaultOverrideMethods {
+		e.overrideMethods[v] = struct{}{}
+	}
+}
+
+// 对POST请求将req.Method改写为请求中指定且在allowed中的方法, form为true时请求头中未指定则从urlencoded表单的_method字段读取
+func overrideMethod(req *http.Request, allowed map[string]struct{}, form bool) error {
+	if req.Method != http.MethodPost {
+		return nil
+	}
+	method := req.Header.Get("X-HTTP-Method-Override")
+	if method == "" && form && strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		if err := req.ParseForm(); err != nil {
+			return err
+		}
+		method = req.PostForm.Get("_method")
+	}
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method == "" {
+		return nil
+	}
+	if _, ok := allowed[method]; !ok {
+		return nil
+	}
+	req.Method = method
+	return nil
+}
@@ -0,0 +1,63 @@
+package easyserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newFormRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+// SetMethodOverride只读取请求头, 不在全局中间件之前读取请求体
+func TestSetMethodOverrideKeepsBody(t *testing.T) {
+	e := newTestEngine()
+	e.SetMethodOverride(true)
+	var body string
+	e.POST("/items", func(c Context) {
+		b, _ := c.GetRawData()
+		body = string(b)
+	})
+	e.DELETE("/items", func(c Context) { body = "deleted" })
+
+	e.ServeHTTP(httptest.NewRecorder(), newFormRequest("_method=DELETE&a=1"))
+	if body != "_method=DELETE&a=1" {
+		t.Fatalf("expected the raw body to reach the POST handler, got %q", body)
+	}
+
+	req := newFormRequest("a=1")
+	req.Header.Set("X-HTTP-Method-Override", "delete")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	if body != "deleted" {
+		t.Fatalf("expected the header to override the method, got %q", body)
+	}
+}
+
+func TestMethodOverrideMiddlewareRespectsBodyLimit(t *testing.T) {
+	e := newTestEngine()
+	e.AppendMiddleware(BodyLimitMiddleware(20))
+	e.AppendMiddleware(MethodOverrideMiddleware())
+	var called string
+	e.DELETE("/items", func(c Context) { called = "DELETE " + c.GetReq().PostForm.Get("a") })
+	e.POST("/items", func(c Context) { called = "POST" })
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, newFormRequest("_method=DELETE&a=1"))
+	if w.Code != http.StatusOK || called != "DELETE 1" {
+		t.Fatalf("expected the form to override the method, got %d %q", w.Code, called)
+	}
+
+	// 未携带Content-Length的请求体超出限制时不改写方法, 也不执行handler
+	called = ""
+	req := newFormRequest("_method=DELETE&a=123456789")
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest || called != "" {
+		t.Fatalf("expected 400 for an oversized chunked form, got %d %q", w.Code, called)
+	}
+}
@@ -0,0 +1,62 @@
+package easyserver
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	metricsOnce     sync.Once
+	requestsTotal   *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+)
+
+func registerMetrics() {
+	metricsOnce.Do(func() {
+		requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total number of HTTP requests.",
+		}, []string{"method", "path", "status"})
+		requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency in seconds.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "path", "status"})
+		prometheus.MustRegister(requestsTotal, requestDuration)
+	})
+}
+
+// Metrics 返回记录http_requests_total及http_request_duration_seconds指标的中间件, 指标注册到prometheus默认registry.
+// path标签为匹配的路由模式(Context.FullPath), 未匹配路由的请求为unmatched, 以避免标签基数过大
+func Metrics() func(c Context) {
+	registerMetrics()
+	return func(c Context) {
+		start := time.Now()
+		c.Next()
+		path := c.FullPath()
+		if path == "" {
+			path = "unmatched"
+		}
+		labels := prometheus.Labels{
+			"method": c.GetReq().Method,
+			"path":   path,
+			"status": strconv.Itoa(c.StatusCode()),
+		}
+		requestsTotal.With(labels).Inc()
+		requestDuration.With(labels).Observe(time.Since(start).Seconds())
+	}
+}
+
+func (e *engine) MetricsEndpoint(path string) {
+	e.Register(Node{
+		Method:  http.MethodGet,
+		Path:    path,
+		Handler: WrapH(promhttp.Handler()),
+		Name:    "promhttp.Handler",
+	})
+}
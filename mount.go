@@ -0,0 +1,44 @@
+package easyserver
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Mount 对GET、HEAD、POST、PUT、PATCH、DELETE、OPTIONS注册prefix+"/*path"路由, 请求经过当前Engine的全局中间件和日志后,
+// 去掉prefix交由sub的ServeHTTP处理, sub的全局中间件、404/405处理及日志同样生效.
+// sub沿用当前Engine生成的log id, 因此两级日志中的log id相同, 响应头中的log id以sub的设置为准,
+// 如不需要两级trace日志可对sub调用SetRequestLogging(false)
+func (e *engine) Mount(prefix string, sub Engine) {
+	h, ok := sub.(http.Handler)
+	if !ok {
+		panic("the mounted engine must implement http.Handler")
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+	handler := func(c Context) {
+		req := c.GetReq()
+		r := new(http.Request)
+		*r = *req
+		u := *req.URL
+		// 使用路由匹配到的参数而非对原始路径去掉prefix, 以免大小写不敏感或清理路径时prefix与原始路径不一致
+		var sub string
+		for _, v := range c.GetParamParam() {
+			if string(v.Key) == "path" {
+				sub = string(v.Value)
+				break
+			}
+		}
+		u.Path = "/" + strings.TrimPrefix(sub, "/")
+		u.RawPath = ""
+		r.URL = &u
+		h.ServeHTTP(c.GetResp(), r)
+	}
+	for _, method := range anyMethods {
+		e.Register(Node{
+			Method:  method,
+			Path:    prefix + "/*path",
+			Handler: handler,
+			Name:    "easyserver.Mount",
+		})
+	}
+}
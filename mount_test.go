@@ -0,0 +1,25 @@
+package easyserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMountStripsPrefixCaseInsensitive(t *testing.T) {
+	sub := newTestEngine()
+	sub.GET("/Items/:id", func(c Context) {
+		_, _ = c.GetResp().Write([]byte(c.GetReq().URL.Path))
+	})
+	e := newTestEngine()
+	e.SetCaseInsensitiveRouting(true)
+	e.Mount("/api", sub)
+
+	for _, path := range []string{"/api/Items/7", "/API/Items/7"} {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK || w.Body.String() != "/Items/7" {
+			t.Errorf("%s: expected 200 with sub path /Items/7, got %d %q", path, w.Code, w.Body.String())
+		}
+	}
+}
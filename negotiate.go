@@ -0,0 +1,111 @@
+package easyserver
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type acceptRange struct {
+	typ     string
+	subtype string
+	q       float64
+}
+
+// 解析Accept头部, 忽略格式不合法的媒体范围
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		slash := strings.IndexByte(mediaType, '/')
+		if slash <= 0 || slash == len(mediaType)-1 {
+			continue
+		}
+		r := acceptRange{
+			typ:     mediaType[:slash],
+			subtype: mediaType[slash+1:],
+			q:       1,
+		}
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if len(p) > 2 && (p[0] == 'q' || p[0] == 'Q') && p[1] == '=' {
+				if q, err := strconv.ParseFloat(p[2:], 64); err == nil && q >= 0 && q <= 1 {
+					r.q = q
+				}
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// 返回mediaType在ranges中最具体的匹配项的q值, 未匹配时返回-1
+func acceptQuality(ranges []acceptRange, mediaType string) float64 {
+	mediaType = strings.ToLower(mediaType)
+	if i := strings.IndexByte(mediaType, ';'); i >= 0 {
+		mediaType = strings.TrimSpace(mediaType[:i])
+	}
+	slash := strings.IndexByte(mediaType, '/')
+	if slash < 0 {
+		return -1
+	}
+	typ, subtype := mediaType[:slash], mediaType[slash+1:]
+
+	q, specificity := -1.0, -1
+	for _, r := range ranges {
+		var s int
+		switch {
+		case r.typ == typ && r.subtype == subtype:
+			s = 2
+		case r.typ == typ && r.subtype == "*":
+			s = 1
+		case r.typ == "*" && r.subtype == "*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
+
+func (c *reqContext) NegotiateFormat(offered ...string) string {
+	if len(offered) == 0 {
+		return ""
+	}
+	accept := c.req.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return offered[0]
+	}
+
+	ranges := parseAccept(accept)
+	best, bestQ := "", 0.0
+	for _, v := range offered {
+		// q相同时优先选取offered中靠前的类型
+		if q := acceptQuality(ranges, v); q > bestQ {
+			best, bestQ = v, q
+		}
+	}
+	return best
+}
+
+func (c *reqContext) Negotiate(code int, data interface{}, offered ...string) error {
+	if len(offered) == 0 {
+		offered = []string{MIMEJSON, MIMEXML}
+	}
+	switch format := c.NegotiateFormat(offered...); format {
+	case MIMEJSON:
+		return c.renderJSON(code, data)
+	case MIMEXML, MIMEXML2:
+		return c.renderXML(code, data)
+	case "":
+		http.Error(c.resp, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+		return errors.New("no acceptable format in offered, offered=" + strings.Join(offered, ","))
+	default:
+		return errors.New("unsupported negotiated format: " + format)
+	}
+}
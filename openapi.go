@@ -0,0 +1,179 @@
+package easyserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// OpenAPIInfo OpenAPI文档的info字段
+type OpenAPIInfo struct {
+	Title       string
+	Description string
+	Version     string
+}
+
+// RouteMetadata 用于生成OpenAPI文档的路由描述
+type RouteMetadata struct {
+	Summary     string
+	Description string
+	Tags        []string
+	RequestBody *OpenAPIBody
+	// key为状态码, 为空时生成描述为OK的200响应
+	Responses map[int]OpenAPIBody
+}
+
+// OpenAPIBody 请求体或响应的描述
+type OpenAPIBody struct {
+	Description string
+	// 默认为application/json, Schema为nil时响应不生成content, 请求体生成不含schema的content
+	ContentType string
+	// JSON Schema, 如map[string]interface{}{"type": "object"}
+	Schema map[string]interface{}
+}
+
+func (e *engine) RegisterWithMeta(method, path string, handler func(c Context), meta RouteMetadata, mws ...func(c Context)) {
+	e.Register(Node{
+		Method:      method,
+		Path:        path,
+		Middlewares: mws,
+		Handler:     handler,
+		Meta:        &meta,
+	})
+}
+
+func (e *engine) OpenAPIEndpoint(path string, info OpenAPIInfo) {
+	e.Register(Node{
+		Method: http.MethodGet,
+		Path:   path,
+		Name:   "easyserver.OpenAPIEndpoint",
+		Handler: func(c Context) {
+			b, err := e.OpenAPISpec(info)
+			if err != nil {
+				_ = c.Error(err)
+				http.Error(c.GetResp(), http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			_ = c.(*reqContext).render(http.StatusOK, MIMEJSON+"; charset=utf-8", b)
+		},
+	})
+}
+
+func (e *engine) OpenAPISpec(info OpenAPIInfo) ([]byte, error) {
+	if e.parent != nil {
+		return e.parent.OpenAPISpec(info)
+	}
+
+	paths := make(map[string]map[string]interface{})
+	e.routeMu.RLock()
+	for key, v := range e.routes {
+		method := strings.ToLower(key[:strings.IndexByte(key, ' ')])
+		// CONNECT等不是OpenAPI定义的操作, 不能作为path item的字段
+		if _, ok := openAPIMethods[method]; !ok {
+			continue
+		}
+		p, params := openAPIPath(v.matchPath)
+		if paths[p] == nil {
+			paths[p] = make(map[string]interface{})
+		}
+		paths[p][method] = openAPIOperation(v.meta, params)
+	}
+	e.routeMu.RUnlock()
+
+	return json.Marshal(map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       info.Title,
+			"description": info.Description,
+			"version":     info.Version,
+		},
+		"paths": paths,
+	})
+}
+
+// OpenAPI 3.0的path item中可以使用的操作
+var openAPIMethods = map[string]struct{}{
+	"get": {}, "put": {}, "post": {}, "delete": {}, "options": {}, "head": {}, "patch": {}, "trace": {},
+}
+
+// openAPIPath 将路由中的:name和*name转换为OpenAPI的{name}, 同时返回路径参数名
+func openAPIPath(p string) (string, []string) {
+	segs := strings.Split(p, "/")
+	var params []string
+	for i, seg := range segs {
+		if seg != "" && (seg[0] == ':' || seg[0] == '*') {
+			params = append(params, seg[1:])
+			segs[i] = "{" + seg[1:] + "}"
+		}
+	}
+	return strings.Join(segs, "/"), params
+}
+
+func openAPIOperation(meta *RouteMetadata, params []string) map[string]interface{} {
+	op := make(map[string]interface{})
+	if len(params) > 0 {
+		ps := make([]interface{}, 0, len(params))
+		for _, name := range params {
+			ps = append(ps, map[string]interface{}{
+				"name":     name,
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]interface{}{"type": "string"},
+			})
+		}
+		op["parameters"] = ps
+	}
+
+	responses := map[string]interface{}{
+		"200": map[string]interface{}{"description": "OK"},
+	}
+	if meta != nil {
+		if meta.Summary != "" {
+			op["summary"] = meta.Summary
+		}
+		if meta.Description != "" {
+			op["description"] = meta.Description
+		}
+		if len(meta.Tags) > 0 {
+			op["tags"] = meta.Tags
+		}
+		if meta.RequestBody != nil {
+			op["requestBody"] = openAPIBody(meta.RequestBody, true)
+		}
+		if len(meta.Responses) > 0 {
+			responses = make(map[string]interface{}, len(meta.Responses))
+			for code, body := range meta.Responses {
+				body := body
+				resp := openAPIBody(&body, false)
+				// OpenAPI要求响应必须有description
+				if _, ok := resp["description"]; !ok {
+					resp["description"] = http.StatusText(code)
+				}
+				responses[strconv.Itoa(code)] = resp
+			}
+		}
+	}
+	op["responses"] = responses
+	return op
+}
+
+// openAPIBody 返回b对应的请求体或响应对象, needContent为true时即使Schema为nil也生成content(OpenAPI要求请求体必须有content)
+func openAPIBody(b *OpenAPIBody, needContent bool) map[string]interface{} {
+	ret := make(map[string]interface{})
+	if b.Description != "" {
+		ret["description"] = b.Description
+	}
+	if b.Schema != nil || needContent {
+		contentType := b.ContentType
+		if contentType == "" {
+			contentType = MIMEJSON
+		}
+		media := make(map[string]interface{})
+		if b.Schema != nil {
+			media["schema"] = b.Schema
+		}
+		ret["content"] = map[string]interface{}{contentType: media}
+	}
+	return ret
+}
@@ -0,0 +1,39 @@
+package easyserver
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OTel 返回OpenTelemetry链路追踪中间件: 通过全局propagator从请求头中提取trace上下文并创建server span,
+// span名称为匹配的路由模式(未匹配路由时为"HTTP "+method), 后续中间件及handler中GetReq().Context()携带该span.
+// tracer为nil时使用otel.Tracer("github.com/gogokit/easyserver")
+func OTel(tracer trace.Tracer) func(c Context) {
+	if tracer == nil {
+		tracer = otel.Tracer("github.com/gogokit/easyserver")
+	}
+	return func(c Context) {
+		req := c.GetReq()
+		ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+		ctx, span := tracer.Start(ctx, "HTTP "+req.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest("", "", req)...),
+		)
+		defer span.End()
+
+		c.SetReq(req.WithContext(ctx))
+		defer c.SetReq(req)
+		c.Next()
+
+		// 路由在Next中匹配, 因此在Next返回后才能获取路由模式
+		if route := c.FullPath(); route != "" {
+			span.SetName(route)
+			span.SetAttributes(semconv.HTTPRouteKey.String(route))
+		}
+		status := c.StatusCode()
+		span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(status)...)
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(status, trace.SpanKindServer))
+	}
+}
@@ -0,0 +1,56 @@
+package easyserver
+
+import (
+	"fmt"
+	"net/http"
+)
+
+const (
+	PanicInGlobalMiddleware = "global middleware"
+	PanicInRouteMiddleware  = "route middleware"
+	PanicInHandler          = "handler"
+)
+
+// MiddlewarePanic 中间件或handler中发生panic时, Next将panic的值包装为*MiddlewarePanic后继续panic,
+// 以便恢复panic时可知道发生的位置; http.ErrAbortHandler不会被包装.
+// 因此中间件中recover()得到的是*MiddlewarePanic而非原始值, 对原始值的类型断言需改为断言Value,
+// 原始值为error时也可通过errors.Is、errors.As判断. 中间件中需要在panic时也执行的清理逻辑应使用defer
+type MiddlewarePanic struct {
+	Value interface{} // 原始的panic值
+	Stage string      // PanicInGlobalMiddleware、PanicInRouteMiddleware或PanicInHandler
+	Index int         // 中间件在全局中间件或路由中间件中的下标
+	Name  string      // 全局中间件的名称, 可能为空
+}
+
+func (p *MiddlewarePanic) Error() string {
+	if p.Name != "" {
+		return fmt.Sprintf("%v (in %s #%d %q)", p.Value, p.Stage, p.Index, p.Name)
+	}
+	if p.Stage == PanicInHandler {
+		return fmt.Sprintf("%v (in %s)", p.Value, p.Stage)
+	}
+	return fmt.Sprintf("%v (in %s #%d)", p.Value, p.Stage, p.Index)
+}
+
+// Unwrap 原始的panic值为error时返回该error, 否则返回nil
+func (p *MiddlewarePanic) Unwrap() error {
+	err, _ := p.Value.(error)
+	return err
+}
+
+// annotatePanic 需通过defer调用, 将当前的panic包装为*MiddlewarePanic, 已包装过的panic保持不变
+func annotatePanic(stage string, index int, name string) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	if _, ok := v.(*MiddlewarePanic); ok || v == http.ErrAbortHandler {
+		panic(v)
+	}
+	panic(&MiddlewarePanic{
+		Value: v,
+		Stage: stage,
+		Index: index,
+		Name:  name,
+	})
+}
@@ -0,0 +1,39 @@
+package easyserver
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testPanicErr struct{ code int }
+
+func (e testPanicErr) Error() string { return "test panic" }
+
+func TestMiddlewarePanicUnwrap(t *testing.T) {
+	e := newTestEngine()
+	var recovered interface{}
+	e.AppendMiddleware(func(c Context) {
+		defer func() { recovered = recover() }()
+		c.Next()
+	})
+	e.GET("/panic", func(c Context) { panic(testPanicErr{code: 7}) })
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	err, ok := recovered.(error)
+	if !ok {
+		t.Fatalf("expected the recovered value to be an error, got %T", recovered)
+	}
+	var mp *MiddlewarePanic
+	if !errors.As(err, &mp) || mp.Stage != PanicInHandler {
+		t.Fatalf("expected a *MiddlewarePanic from the handler, got %v", err)
+	}
+	var target testPanicErr
+	if !errors.As(err, &target) || target.code != 7 {
+		t.Fatalf("expected errors.As to reach the original error, got %v", err)
+	}
+	if (&MiddlewarePanic{Value: "not an error"}).Unwrap() != nil {
+		t.Fatal("expected Unwrap to return nil for a non-error value")
+	}
+}
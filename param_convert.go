@@ -0,0 +1,91 @@
+package easyserver
+
+import (
+	"strconv"
+
+	"github.com/gogokit/router"
+)
+
+// ParamConverter 在路由匹配时校验并转换路径参数, 返回false时视为该路由未匹配
+type ParamConverter func(value string) (interface{}, bool)
+
+// IntParam 将路径参数转换为int的ParamConverter
+func IntParam(value string) (interface{}, bool) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, false
+	}
+	return n, true
+}
+
+func (e *engine) RegisterWithParams(method, path string, handler func(c Context), converters map[string]ParamConverter, mws ...func(c Context)) {
+	e.Register(Node{
+		Method:      method,
+		Path:        path,
+		Middlewares: mws,
+		Handler:     handler,
+		Converters:  converters,
+	})
+}
+
+// 检查converters中的参数均在路由模式path中出现
+func checkConverters(path string, converters map[string]ParamConverter) {
+	if len(converters) == 0 {
+		return
+	}
+	names := make(map[string]struct{})
+	for _, p := range extractParams(path, path) {
+		names[string(p.Key)] = struct{}{}
+	}
+	for k, v := range converters {
+		if _, ok := names[k]; !ok {
+			panic("param converter for unknown param: " + k + ", path=" + path)
+		}
+		if v == nil {
+			panic("param converter of " + k + " is nil")
+		}
+	}
+}
+
+// 依次转换urlParams中设置了ParamConverter的参数, 任一转换失败时返回false
+func convertParams(converters map[string]ParamConverter, urlParams []router.UrlParam) (map[string]interface{}, bool) {
+	if len(converters) == 0 {
+		return nil, true
+	}
+	converted := make(map[string]interface{}, len(converters))
+	for _, p := range urlParams {
+		conv, ok := converters[string(p.Key)]
+		if !ok {
+			continue
+		}
+		v, ok := conv(string(p.Value))
+		if !ok {
+			return nil, false
+		}
+		converted[string(p.Key)] = v
+	}
+	return converted, true
+}
+
+func (c *reqContext) Param(key string) interface{} {
+	if v, ok := c.params[key]; ok {
+		return v
+	}
+	for _, p := range c.pathParam {
+		if string(p.Key) == key {
+			return string(p.Value)
+		}
+	}
+	return nil
+}
+
+func (c *reqContext) GetInt(key string) (int, bool) {
+	switch v := c.Param(key).(type) {
+	case int:
+		return v, true
+	case string:
+		n, err := strconv.Atoi(v)
+		return n, err == nil
+	}
+	return 0, false
+}
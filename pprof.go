@@ -0,0 +1,45 @@
+package easyserver
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"strings"
+)
+
+func (e *engine) EnablePprof(prefix string, mws ...func(c Context)) {
+	path := strings.TrimSuffix(prefix, "/") + "/debug/pprof/*name"
+	handler := func(c Context) {
+		var name string
+		for _, v := range c.GetParamParam() {
+			if string(v.Key) == "name" {
+				name = string(v.Value)
+				break
+			}
+		}
+
+		switch name {
+		case "":
+			// 非默认前缀时pprof.Index无法从路径中解析出profile名称, 此时只展示索引页
+			pprof.Index(c.GetResp(), c.GetReq())
+		case "cmdline":
+			pprof.Cmdline(c.GetResp(), c.GetReq())
+		case "profile":
+			pprof.Profile(c.GetResp(), c.GetReq())
+		case "symbol":
+			pprof.Symbol(c.GetResp(), c.GetReq())
+		case "trace":
+			pprof.Trace(c.GetResp(), c.GetReq())
+		default:
+			pprof.Handler(name).ServeHTTP(c.GetResp(), c.GetReq())
+		}
+	}
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		e.Register(Node{
+			Method:      method,
+			Path:        path,
+			Middlewares: mws,
+			Handler:     handler,
+			Name:        "net/http/pprof",
+		})
+	}
+}
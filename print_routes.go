@@ -0,0 +1,71 @@
+package easyserver
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/term"
+)
+
+var methodColors = map[string]string{
+	"GET":     "\x1b[32m",
+	"POST":    "\x1b[33m",
+	"PUT":     "\x1b[34m",
+	"PATCH":   "\x1b[36m",
+	"DELETE":  "\x1b[31m",
+	"HEAD":    "\x1b[35m",
+	"OPTIONS": "\x1b[37m",
+}
+
+func (e *engine) PrintRoutes(w io.Writer) {
+	if e.parent != nil {
+		e.parent.PrintRoutes(w)
+		return
+	}
+
+	type route struct {
+		method, path, handler string
+	}
+	e.routeMu.RLock()
+	routes := make([]route, 0, len(e.routes))
+	for key, v := range e.routes {
+		// key为routeKey(method, path)
+		method := key[:strings.IndexByte(key, ' ')]
+		name := v.name
+		if name == "" {
+			name = funcName(v.handler)
+		}
+		routes = append(routes, route{method: method, path: v.matchPath, handler: name})
+	}
+	e.routeMu.RUnlock()
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].path != routes[j].path {
+			return routes[i].path < routes[j].path
+		}
+		return routes[i].method < routes[j].method
+	})
+
+	colored := false
+	if f, ok := w.(*os.File); ok {
+		colored = term.IsTerminal(int(f.Fd()))
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "METHOD\tPATH\tHANDLER")
+	for _, v := range routes {
+		method := v.method
+		if colored {
+			// 所有方法使用等长的转义序列, 保证列对齐
+			color, ok := methodColors[method]
+			if !ok {
+				color = "\x1b[39m"
+			}
+			method = color + method + "\x1b[0m"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", method, v.path, v.handler)
+	}
+	_ = tw.Flush()
+}
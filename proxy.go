@@ -0,0 +1,58 @@
+package easyserver
+
+import (
+	"errors"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+
+	"github.com/gogokit/logs"
+)
+
+// Proxy 对GET、HEAD、POST、PUT、PATCH、DELETE、OPTIONS注册path路由并将请求转发到target.
+// path以通配符'*'结尾时转发的路径为target的路径加上通配符匹配到的部分, 否则为target的路径加上请求路径.
+// 转发的请求会携带log id请求头, X-Forwarded-For由httputil.ReverseProxy追加客户端IP.
+// 暂不支持WebSocket等协议升级请求
+func (e *engine) Proxy(path, target string) error {
+	u, err := url.Parse(target)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return errors.New("proxy target must be an absolute url, target=" + target)
+	}
+
+	var wildcard string
+	if idx := strings.LastIndexByte(path, '*'); idx >= 0 {
+		wildcard = path[idx+1:]
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(u)
+	handler := func(c Context) {
+		req := c.GetReq()
+		r := req.Clone(req.Context())
+		if wildcard != "" {
+			for _, v := range c.GetParamParam() {
+				if string(v.Key) == wildcard {
+					r.URL.Path = "/" + strings.TrimPrefix(string(v.Value), "/")
+					r.URL.RawPath = ""
+					break
+				}
+			}
+		}
+		if logId := logs.GetLogId(req.Context()); logId != "" {
+			r.Header.Set(e.logIdHeader, logId)
+		}
+		proxy.ServeHTTP(c.GetResp(), r)
+	}
+
+	for _, method := range anyMethods {
+		e.Register(Node{
+			Method:  method,
+			Path:    path,
+			Handler: handler,
+			Name:    "easyserver.Proxy " + target,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package easyserver
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"strconv"
+)
+
+const (
+	MIMEJSON = "application/json"
+	MIMEXML  = "application/xml"
+	MIMEXML2 = "text/xml"
+)
+
+// 将body以contentType和状态码code写入响应
+func (c *reqContext) render(code int, contentType string, body []byte) error {
+	header := c.resp.Header()
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	c.resp.WriteHeader(code)
+	if !bodyAllowedForStatus(code) || c.req.Method == http.MethodHead {
+		return nil
+	}
+	_, err := c.resp.Write(body)
+	return err
+}
+
+func (c *reqContext) renderJSON(code int, obj interface{}) error {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return c.render(code, MIMEJSON+"; charset=utf-8", b)
+}
+
+func (c *reqContext) renderXML(code int, obj interface{}) error {
+	b, err := xml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return c.render(code, MIMEXML+"; charset=utf-8", b)
+}
+
+// 返回状态码为code的响应是否允许包含响应体
+func bodyAllowedForStatus(code int) bool {
+	switch {
+	case code >= 100 && code <= 199:
+		return false
+	case code == http.StatusNoContent, code == http.StatusNotModified:
+		return false
+	}
+	return true
+}
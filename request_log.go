@@ -0,0 +1,137 @@
+package easyserver
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/gogokit/tostr"
+)
+
+// RequestLogConfig 控制默认请求trace日志中记录的字段, Method、Path、Proto、Host、RemoteAddr、ContentLength等基础字段总是记录
+type RequestLogConfig struct {
+	LogHeaders     bool // 记录请求头和Trailer
+	LogQueryParams bool // 记录URL中的查询参数
+	LogForm        bool // 记录已解析的Form、PostForm、MultipartForm
+	LogBody        bool // 记录请求体, 读取的内容会重新放回请求体中
+	MaxBodySize    int  // 记录请求体的最大字节数, 小于等于0时为4096
+	// 记录请求头时需要脱敏的请求头, 为nil时脱敏Authorization和Cookie
+	RedactHeaders []string
+}
+
+// DefaultRequestLogConfig 返回默认配置, 记录请求头、查询参数和表单, 不记录请求体
+func DefaultRequestLogConfig() RequestLogConfig {
+	return RequestLogConfig{
+		LogHeaders:     true,
+		LogQueryParams: true,
+		LogForm:        true,
+	}
+}
+
+func (e *engine) SetRequestLog(cfg RequestLogConfig) {
+	e.mustBeRoot("SetRequestLog")
+	e.requestLog = cfg
+}
+
+const redacted = "[REDACTED]"
+
+func (e *engine) logRequest(req *http.Request) {
+	cfg := e.requestLog
+	var (
+		u             = req.URL
+		requestURI    = req.RequestURI
+		header        interface{}
+		trailer       interface{}
+		form          interface{}
+		postForm      interface{}
+		multipartForm interface{}
+		body          interface{}
+	)
+
+	if !cfg.LogQueryParams && u != nil && u.RawQuery != "" {
+		copied := *u
+		copied.RawQuery = ""
+		u = &copied
+		requestURI = (&url.URL{Path: u.Path, RawPath: u.RawPath}).RequestURI()
+	}
+
+	if cfg.LogHeaders {
+		redactHeaders := cfg.RedactHeaders
+		if redactHeaders == nil {
+			redactHeaders = []string{"Authorization", "Cookie"}
+		}
+		header = redactHeader(req.Header, redactHeaders)
+		trailer = req.Trailer
+	}
+
+	if cfg.LogForm {
+		form, postForm, multipartForm = req.Form, req.PostForm, req.MultipartForm
+	}
+
+	if cfg.LogBody && req.Body != nil && req.Body != http.NoBody {
+		maxSize := cfg.MaxBodySize
+		if maxSize <= 0 {
+			maxSize = 4096
+		}
+		b, _ := io.ReadAll(io.LimitReader(req.Body, int64(maxSize)))
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{
+			Reader: io.MultiReader(bytes.NewReader(b), req.Body),
+			Closer: req.Body,
+		}
+		body = string(b)
+	}
+
+	e.logger.Trace(req.Context(), "[EasyServer] Req=%v", tostr.String(&struct {
+		Method           interface{}
+		URL              interface{}
+		Proto            interface{}
+		ProtoMajor       interface{}
+		ProtoMinor       interface{}
+		Header           interface{}
+		Host             interface{}
+		Form             interface{}
+		PostForm         interface{}
+		MultipartForm    interface{}
+		Trailer          interface{}
+		RemoteAddr       interface{}
+		RequestURI       interface{}
+		ContentLength    interface{}
+		TransferEncoding interface{}
+		Body             interface{}
+	}{
+		Method:           req.Method,
+		URL:              u,
+		Proto:            req.Proto,
+		ProtoMajor:       req.ProtoMajor,
+		ProtoMinor:       req.ProtoMinor,
+		Header:           header,
+		Host:             req.Host,
+		Form:             form,
+		PostForm:         postForm,
+		MultipartForm:    multipartForm,
+		Trailer:          trailer,
+		RemoteAddr:       req.RemoteAddr,
+		RequestURI:       requestURI,
+		ContentLength:    req.ContentLength,
+		TransferEncoding: req.TransferEncoding,
+		Body:             body,
+	}))
+}
+
+// 返回header的副本, 其中names对应的头部的值被替换为redacted
+func redactHeader(header http.Header, names []string) http.Header {
+	if len(names) == 0 {
+		return header
+	}
+	copied := header.Clone()
+	for _, name := range names {
+		if _, ok := copied[http.CanonicalHeaderKey(name)]; ok {
+			copied[http.CanonicalHeaderKey(name)] = []string{redacted}
+		}
+	}
+	return copied
+}
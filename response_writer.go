@@ -0,0 +1,142 @@
+package easyserver
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// responseWriter 包装http.ResponseWriter, 记录响应的状态码及写入的字节数
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	size   int
+	// 为true时在写入状态码前设置Server-Timing响应头, 耗时从start开始计算
+	serverTiming bool
+	start        time.Time
+}
+
+// Reset 重新初始化w以包装rw
+func (w *responseWriter) Reset(rw http.ResponseWriter) {
+	w.ResponseWriter = rw
+	w.status = 0
+	w.size = 0
+	w.serverTiming = false
+	w.start = time.Time{}
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.status != 0 {
+		return
+	}
+	// 1xx(101除外)为信息性响应, 之后还可以再写入最终的状态码
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+	if w.serverTiming {
+		w.setServerTiming()
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) setServerTiming() {
+	dur := float64(time.Since(w.start)) / float64(time.Millisecond)
+	w.Header().Set("Server-Timing", "app;dur="+strconv.FormatFloat(dur, 'f', 3, 64))
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+	return n, err
+}
+
+// 返回响应的状态码, 尚未写入时返回200
+func (w *responseWriter) StatusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// 返回是否已经写入了状态码
+func (w *responseWriter) Written() bool {
+	return w.status != 0
+}
+
+// responseWritten 返回经由w是否已经写入了响应, 依次通过Unwrap查找实现了Written的包装,
+// 缓存响应的包装(如ETagMiddleware)需实现Written以反映已缓存但尚未写出的内容
+func responseWritten(w http.ResponseWriter) bool {
+	for w != nil {
+		if r, ok := w.(interface{ Written() bool }); ok {
+			return r.Written()
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return false
+		}
+		w = u.Unwrap()
+	}
+	return false
+}
+
+func (w *responseWriter) Flush() {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the ResponseWriter doesn't support the Hijacker interface")
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil && w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// headResponseWriter 丢弃写入的响应体, 用于使用GET路由处理HEAD请求, 响应头(包括Content-Length)保持不变
+type headResponseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *headResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *headResponseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return len(b), nil
+}
+
+func (w *headResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *headResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
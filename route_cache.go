@@ -0,0 +1,159 @@
+package easyserver
+
+import (
+	"container/list"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/gogokit/router"
+)
+
+// routeCache 以method+path为key缓存查找到的路由, 只缓存路由本身, 路径参数每次按路由的模式从请求路径中重新解析
+type routeCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[routeCacheKey]*list.Element
+}
+
+// 使用结构体而非拼接的字符串作为key, 查找时无需分配内存
+type routeCacheKey struct {
+	method, path string
+}
+
+type routeCacheEntry struct {
+	key   routeCacheKey
+	value *routerValue
+	// 路由模式中的参数名, 按出现顺序排列, 解析路径参数时直接使用
+	paramKeys [][]byte
+	// 为true时表示HEAD请求使用的是GET路由
+	headFallback bool
+}
+
+func newRouteCache(capacity int) *routeCache {
+	return &routeCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[routeCacheKey]*list.Element),
+	}
+}
+
+// rc为nil时表示未开启缓存, 总是返回false
+func (rc *routeCache) get(method, path string) (*routeCacheEntry, bool) {
+	if rc == nil {
+		return nil, false
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	e, ok := rc.items[routeCacheKey{method, path}]
+	if !ok {
+		return nil, false
+	}
+	rc.ll.MoveToFront(e)
+	return e.Value.(*routeCacheEntry), true
+}
+
+func (rc *routeCache) add(method, path string, value *routerValue, headFallback bool) {
+	if rc == nil {
+		return
+	}
+	entry := &routeCacheEntry{
+		key:          routeCacheKey{method, path},
+		value:        value,
+		paramKeys:    paramKeysOf(value.matchPath),
+		headFallback: headFallback,
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if _, ok := rc.items[entry.key]; ok {
+		return
+	}
+	rc.items[entry.key] = rc.ll.PushFront(entry)
+	for rc.ll.Len() > rc.capacity {
+		e := rc.ll.Back()
+		rc.ll.Remove(e)
+		delete(rc.items, e.Value.(*routeCacheEntry).key)
+	}
+}
+
+func (rc *routeCache) purge() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.ll.Init()
+	rc.items = make(map[routeCacheKey]*list.Element)
+}
+
+// params 按entry中路由的模式从path中解析路径参数
+func (entry *routeCacheEntry) params(path string) []router.UrlParam {
+	return extractParamsWithKeys(entry.value.matchPath, path, entry.paramKeys)
+}
+
+func (e *engine) SetRouteCacheSize(n int) {
+	e.mustBeRoot("SetRouteCacheSize")
+	e.routeMu.Lock()
+	defer e.routeMu.Unlock()
+	if n <= 0 {
+		e.routeCache = nil
+		return
+	}
+	e.routeCache = newRouteCache(n)
+}
+
+// extractParams 按路由模式pattern从path中解析路径参数, 要求path或其小写形式能够匹配pattern
+func extractParams(pattern, path string) []router.UrlParam {
+	return extractParamsWithKeys(pattern, path, paramKeysOf(pattern))
+}
+
+// paramKeysOf 返回路由模式pattern中按出现顺序排列的参数名
+func paramKeysOf(pattern string) [][]byte {
+	var keys [][]byte
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != ':' && pattern[i] != '*' {
+			continue
+		}
+		k := i + 1
+		for k < len(pattern) && pattern[k] != '/' {
+			k++
+		}
+		keys = append(keys, []byte(pattern[i+1:k]))
+		i = k
+	}
+	return keys
+}
+
+// extractParamsWithKeys 同extractParams, keys为paramKeysOf(pattern)的结果, 参数值共用一次分配的内存
+func extractParamsWithKeys(pattern, path string, keys [][]byte) []router.UrlParam {
+	if len(keys) == 0 {
+		return nil
+	}
+	params := make([]router.UrlParam, 0, len(keys))
+	b := []byte(path)
+	i, j := 0, 0
+	for i < len(pattern) && j <= len(path) {
+		switch c := pattern[i]; {
+		case c == '*':
+			params = append(params, router.UrlParam{Key: keys[len(params)], Value: b[j:]})
+			return params
+		case c == ':':
+			for i < len(pattern) && pattern[i] != '/' {
+				i++
+			}
+			v := j
+			for v < len(path) && path[v] != '/' {
+				v++
+			}
+			params = append(params, router.UrlParam{Key: keys[len(params)], Value: b[j:v:v]})
+			j = v
+		case c < utf8.RuneSelf && j < len(path) && path[j] < utf8.RuneSelf:
+			i++
+			j++
+		default:
+			// path可能是转换为小写后匹配pattern的原始路径, 按字符逐个前进以保持对齐
+			_, n := utf8.DecodeRuneInString(pattern[i:])
+			_, m := utf8.DecodeRuneInString(path[j:])
+			i += n
+			j += m
+		}
+	}
+	return params
+}
@@ -0,0 +1,60 @@
+package easyserver
+
+import "time"
+
+// SecureConfig 安全相关响应头的配置, 字段为空串或false时不设置对应的响应头
+type SecureConfig struct {
+	ContentSecurityPolicy string // Content-Security-Policy
+	FrameOptions          string // X-Frame-Options
+	ContentTypeNosniff    bool   // 为true时设置X-Content-Type-Options: nosniff
+	ReferrerPolicy        string // Referrer-Policy
+	PermissionsPolicy     string // Permissions-Policy
+	// 大于0时对https请求(req.TLS不为nil)设置Strict-Transport-Security, http请求总是不设置
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubDomains bool
+	HSTSPreload           bool
+}
+
+// DefaultSecureConfig 返回默认配置: 禁止被嵌入frame, 禁止MIME类型嗅探, 不发送Referer
+func DefaultSecureConfig() SecureConfig {
+	return SecureConfig{
+		FrameOptions:       "DENY",
+		ContentTypeNosniff: true,
+		ReferrerPolicy:     "no-referrer",
+	}
+}
+
+// SecureMiddleware 返回按cfg设置安全相关响应头的中间件
+func SecureMiddleware(cfg SecureConfig) func(c Context) {
+	var hsts string
+	if cfg.HSTSMaxAge > 0 {
+		hsts = hstsValue(cfg.HSTSMaxAge, cfg.HSTSIncludeSubDomains, cfg.HSTSPreload)
+	}
+	return func(c Context) {
+		header := c.GetResp().Header()
+		if hsts != "" && c.GetReq().TLS != nil {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+		if cfg.ContentSecurityPolicy != "" {
+			header.Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
+		}
+		if cfg.FrameOptions != "" {
+			header.Set("X-Frame-Options", cfg.FrameOptions)
+		}
+		if cfg.ContentTypeNosniff {
+			header.Set("X-Content-Type-Options", "nosniff")
+		}
+		if cfg.ReferrerPolicy != "" {
+			header.Set("Referrer-Policy", cfg.ReferrerPolicy)
+		}
+		if cfg.PermissionsPolicy != "" {
+			header.Set("Permissions-Policy", cfg.PermissionsPolicy)
+		}
+		c.Next()
+	}
+}
+
+// SecureHeaders 同SecureMiddleware
+func SecureHeaders(cfg SecureConfig) func(c Context) {
+	return SecureMiddleware(cfg)
+}
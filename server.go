@@ -1,11 +1,28 @@
 package easyserver
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"html/template"
+	"io"
+	"mime/multipart"
+	"net"
 	"net/http"
+	"path"
+	"reflect"
+	"runtime"
 	"runtime/debug"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/gogokit/logs"
 	"github.com/gogokit/router"
@@ -23,62 +40,486 @@ type Node struct {
 	Path        string
 	Middlewares []func(c Context)
 	Handler     func(c Context)
+	Name        string // 路由名称, 用于标识路由, 设置后Context.HandlerName及PrintRoutes使用该名称
+	// 用于生成OpenAPI文档的路由描述, 可为nil
+	Meta *RouteMetadata
+	// 以路径参数名为key的ParamConverter, 任一参数转换失败时视为未匹配该路由, 可为nil
+	Converters map[string]ParamConverter
 }
 
 type Engine interface {
 	Register(node Node)
+	// 注册带有路由级中间件的路由, 请求依次经过全局中间件、mws和handler
+	RegisterWithMiddlewares(method, path string, handler func(c Context), mws ...func(c Context))
+	// 注册net/http风格的handler
+	Handle(method, path string, handler http.HandlerFunc, mws ...func(c Context))
+	// GET、POST、PUT、PATCH、DELETE分别同RegisterWithMiddlewares(对应的method, path, handler, mws...)
+	GET(path string, handler func(c Context), mws ...func(c Context))
+	POST(path string, handler func(c Context), mws ...func(c Context))
+	PUT(path string, handler func(c Context), mws ...func(c Context))
+	PATCH(path string, handler func(c Context), mws ...func(c Context))
+	DELETE(path string, handler func(c Context), mws ...func(c Context))
+	// 为GET、HEAD、POST、PUT、PATCH、DELETE、OPTIONS注册path对应的路由
+	Any(path string, handler func(c Context), mws ...func(c Context))
+	// 为methods中的请求方法注册path对应的路由
+	Match(methods []string, path string, handler func(c Context), mws ...func(c Context))
 	RegisterGroup(group Group)
+	// 返回RootPath为prefix/version的Group, Group中的路由可通过Context.Get(APIVersionKey)获取version,
+	// 需在设置Children后通过RegisterGroup注册
+	APIVersion(version, prefix string) Group
+	// 返回注册路由时自动添加prefix的子Engine, 可交给其他包注册路由. 子Engine注册的路由保存在当前Engine中,
+	// 请求先经过当前Engine的全局中间件, 再经过子Engine的全局中间件(在注册路由时确定, 之后追加的不影响已注册的路由).
+	// 子Engine上只能调用注册路由、添加中间件及SetErrorHandler(只作用于子Engine注册的路由, 未设置时使用当前Engine的),
+	// PrintRoutes、OpenAPISpec返回当前Engine的全部路由, 其余只对处理请求生效的设置(如SetValidator、NoRoute、Run*等)在子Engine上调用时panic
+	Subrouter(prefix string) Engine
+	// 返回与当前Engine配置及全局中间件相同但没有注册任何路由的新Engine, 二者之后的修改互不影响.
+	// 已启动的服务及OnStartup、OnShutdown添加的函数不复制, 对Subrouter返回的Engine调用时返回独立的Engine
+	Clone() Engine
+	// 将已注册的路由按路径、方法排序后以表格形式写入w, w为终端时方法以不同颜色显示
+	PrintRoutes(w io.Writer)
+	// 注册带有OpenAPI描述的路由, 同RegisterWithMiddlewares
+	RegisterWithMeta(method, path string, handler func(c Context), meta RouteMetadata, mws ...func(c Context))
+	// 根据已注册的路由及其RouteMetadata生成OpenAPI 3.0的json文档
+	OpenAPISpec(info OpenAPIInfo) ([]byte, error)
+	// 注册在匹配时通过converters校验并转换路径参数的路由, 转换失败时视为未匹配(按404或NoRoute处理),
+	// 转换后的值可通过Context.Param获取, 其余同RegisterWithMiddlewares
+	RegisterWithParams(method, path string, handler func(c Context), converters map[string]ParamConverter, mws ...func(c Context))
+	// 注册返回OpenAPISpec(info)的GET路由, 可供Swagger UI等使用, 每次请求时重新生成
+	OpenAPIEndpoint(path string, info OpenAPIInfo)
+	// 依次注册nodes中的路由
+	RegisterRoutes(nodes []Node)
+	// 同RegisterRoutes, 但在注册前检查nodes之间及与已注册路由之间是否存在method和path均相同的路由, 存在时panic且不注册任何路由
+	MustRegisterRoutes(nodes []Node)
+	// 注册返回error的handler, handler返回非nil且尚未写入响应时调用SetErrorHandler设置的处理函数
+	RegisterE(method, path string, handler func(c Context) error, mws ...func(c Context))
+	GETE(path string, handler func(c Context) error, mws ...func(c Context))
+	POSTE(path string, handler func(c Context) error, mws ...func(c Context))
+	// 设置RegisterE注册的handler返回错误时的处理函数, 默认打印错误日志并响应500
+	SetErrorHandler(h func(c Context, err error))
 	RunHttp(port int) error
 	RunHttps(port int, certFile, keyFile string) error
+	// 添加在RunHttp/RunHttps绑定端口后、开始处理请求前执行的函数
+	OnStartup(fn func())
+	// 添加在Shutdown等待进行中的请求处理完成后执行的函数
+	OnShutdown(fn func(ctx context.Context) error)
+	// 优雅关闭通过RunHttp/RunHttps启动的服务: 停止接受新连接并等待进行中的请求处理完成, 之后依次执行OnShutdown添加的函数,
+	// 返回过程中的所有错误. 关闭后RunHttp/RunHttps返回http.ErrServerClosed
+	Shutdown(ctx context.Context) error
+	// 启动服务并阻塞直到收到SIGINT或SIGTERM, 之后调用Shutdown优雅关闭, certFile和keyFile均为空时使用http.
+	// 启动服务失败时立即返回错误
+	RunUntilSignal(port int, certFile, keyFile string) error
+	// 同RunUntilSignal(port, "", "")
+	RunHttpUntilSignal(port int) error
+	// 设置RunUntilSignal等待进行中的请求处理完成的最长时间, 默认为10s
+	SetShutdownTimeout(d time.Duration)
+	// 开启或关闭默认的请求/响应trace日志, 默认开启
+	SetRequestLogging(enable bool)
+	// 设置不打印请求/响应trace日志的路径
+	SetLogSkipPaths(paths []string)
+	// 设置默认请求trace日志中记录的字段
+	SetRequestLog(cfg RequestLogConfig)
+	// 设置Engine输出日志使用的Logger, 默认为LogsAdapter
+	SetLogger(l Logger)
+	// 开启后查找路由前将请求路径转换为小写, 此时注册的路由应全部为小写, 路径参数保留原始大小写, 原始路径仍可通过GetReq().URL.Path获取
+	SetCaseInsensitiveRouting(enable bool)
+	// 开启后请求路径中含有多余的'/'或'.'、'..'路径段时永久重定向到清理后的路径
+	SetCleanPath(enable bool)
+	// 设置路径为空的请求308重定向的目标路径(保留查询参数), 默认为"/", 为空串时不重定向而是按未匹配路由处理
+	SetEmptyPathRedirect(target string)
+	// 开启后对POST请求根据X-HTTP-Method-Override请求头将req.Method改写为PUT、PATCH或DELETE,
+	// 在全局中间件执行前改写, 路由、405判断及之后的中间件和handler均只能看到改写后的方法, 默认关闭.
+	// 不读取_method表单字段, 以免在BodyLimitMiddleware等全局中间件之前读取请求体, 需要时使用MethodOverrideMiddleware
+	SetMethodOverride(enable bool)
+	// 设置首页路径, 请求"/"且没有与之匹配的路由时302重定向到path(保留查询参数), 路径为空的请求先按SetEmptyPathRedirect重定向到"/".
+	// 默认为"/index", 设置为空串时不重定向
+	SetIndexPath(path string)
+	// 设置未匹配任何路由时的handler, 代替默认的404响应(如用于单页应用或转发到旧服务), 此时路径参数为空、GetMatchPath返回空串.
+	// 末尾'/'重定向、SetRedirectFixedPath及SetIndexPath的重定向优先于h, 请求方法未注册任何路由时仍响应405; h为nil时恢复默认的404
+	NoRoute(h func(c Context))
+	// 开启后在写入响应头时设置Server-Timing: app;dur=<ms>, dur为从开始处理请求到写入响应头的耗时, 默认关闭
+	SetServerTimingHeader(enable bool)
+	// 设置路由缓存容量, 开启后以请求方法和路径为key缓存查找到的路由(不缓存路径参数), 超过容量时淘汰最久未使用的路由,
+	// 注册路由时清空缓存; n小于等于0时关闭缓存, 默认关闭
+	SetRouteCacheSize(n int)
+	// 开启后请求路径未找到路由时, 尝试清理路径及将路径转换为小写后查找, 找到时将静态部分的大小写修正为注册的路由(路径参数的值不变)后重定向, 默认关闭
+	SetRedirectFixedPath(enable bool)
+	// 设置校验器, 设置后Context的Bind*方法解析成功后会调用v校验结果并返回其错误
+	SetValidator(v func(obj interface{}) error)
+	// 设置注册method和path均与已注册路由相同的路由时的处理策略, 默认为PanicOnDuplicate
+	SetDuplicateRoutePolicy(policy DuplicatePolicy)
+	// 设置返回log id的响应头名称, 默认为logs.LogIdContextKey
+	SetLogIdHeader(name string)
+	// 设置log id生成函数, 默认为logs.GenLogId
+	SetLogIdGenerator(gen func() string)
+	// 设置读取上游关联id的请求头, 请求中带有该请求头时使用其值作为log id, 默认读取X-Request-ID和X-Correlation-ID
+	SetCorrelationIdHeader(name string)
+	// 追加全局中间件, 全局中间件作用于所有请求且在路由查找之前执行
+	AppendMiddleware(handler func(c Context))
+	// 追加具名的全局中间件, 可通过RemoveMiddleware按名称移除, 可在处理请求的同时调用, 之后到达的请求生效
+	AppendNamedMiddleware(name string, handler func(c Context))
+	// 移除名称为name的全局中间件, 返回是否存在该中间件, name为空串时panic, 可在处理请求的同时调用
+	RemoveMiddleware(name string) bool
+	// 按执行顺序返回当前全局中间件的快照, 通过AppendMiddleware等追加的中间件名称为空串
+	Middlewares() []MiddlewareInfo
+	// 追加仅在cond返回true时执行的全局中间件, cond返回false时直接执行下一个中间件
+	UseIf(cond func(req *http.Request) bool, mw func(c Context))
+	// 追加仅对methods中的请求方法执行的全局中间件
+	UseForMethods(methods []string, mw func(c Context))
+	// 追加仅对路径以pathPrefix开头的请求执行的全局中间件, 开启SetCaseInsensitiveRouting或SetCleanPath时使用转换后的路径判断
+	AppendMiddlewareFor(pathPrefix string, handler func(c Context))
+	// 将net/http风格的中间件追加为全局中间件
+	UseHTTPMiddleware(mw func(http.Handler) http.Handler)
+	// 注册健康检查的GET路由, 所有check均返回nil时响应200和ok, 否则响应503和失败的check
+	Health(path string, checks ...func() error)
+	// 注册返回filepath文件的GET /favicon.ico路由, 响应允许客户端缓存一年
+	Favicon(filepath string)
+	// 注册GET /favicon.ico路由并响应204, 同时不再为该路径记录请求日志; 之后调用SetLogSkipPaths会覆盖该设置
+	FaviconIgnore()
+	// 在prefix+"/debug/pprof/"下注册net/http/pprof的handler, mws可用于添加鉴权等中间件
+	// 注意: 不要在对公网提供服务的Engine上开启
+	EnablePprof(prefix string, mws ...func(c Context))
+	// 将sub挂载到prefix下, prefix下的所有请求去掉prefix后交由sub处理
+	Mount(prefix string, sub Engine)
+	// 将path对应的请求反向代理到target
+	Proxy(path, target string) error
+	// 解析匹配pattern的模板文件, 供Context.HTML使用, 解析失败时panic
+	LoadHTMLGlob(pattern string)
+	// 解析files中的模板文件, 供Context.HTML使用, 解析失败时panic
+	LoadHTMLFiles(files ...string)
+	// 注册暴露prometheus默认registry中指标的GET路由
+	MetricsEndpoint(path string)
+	// 设置所有请求的基础context, 请求的context中查找不到的值会从ctx中查找, 请求的取消和超时不受ctx影响
+	SetBaseContext(ctx context.Context)
+	// 设置同时处理的最大请求数, 小于等于0时不限制(默认), 需在开始处理请求前设置
+	SetMaxConcurrent(n int)
+	// 设置达到最大请求数时新请求的最长等待时间, 超时后响应503, 默认为0即立即响应503
+	SetMaxConcurrentWait(d time.Duration)
 }
 
 type Context interface {
+	// Deadline、Done、Err均委托给GetReq().Context(), Value优先返回通过Set保存的值, 因此Context可以直接作为context.Context使用
+	context.Context
 	GetReq() *http.Request
+	// 替换之后的中间件及handler通过GetReq获取的*http.Request, 通常需要在Next返回后恢复
+	SetReq(req *http.Request)
+	// 将请求的context替换为ctx, 之后的中间件及handler中GetReq().Context()及Deadline、Done、Err、Value均基于ctx
+	SetRequestContext(ctx context.Context)
 	GetResp() http.ResponseWriter
+	// 替换之后的中间件及handler通过GetResp获取的http.ResponseWriter, 用于在中间件中包装响应, 通常需要在Next返回后恢复
+	SetResp(resp http.ResponseWriter)
 	GetParamParam() []router.UrlParam
+	// 返回路径参数key的值, 路由为key设置了ParamConverter时为转换后的值, 否则为string, 不存在时返回nil
+	Param(key string) interface{}
+	// 返回路径参数key的int值, Param(key)为int或可解析为int的string时返回true
+	GetInt(key string) (int, bool)
 	GetMatchPath() string
+	// 返回匹配的路由模式(如/user/:id), 同GetMatchPath, 未匹配路由时返回空串
+	FullPath() string
+	// 返回路由的Node.Name, 未设置时返回handler的函数全名; RegisterE、Handle注册的路由为传入的函数名称
+	HandlerName() string
 	Next() bool
+	// 终止中间件链, 之后调用Next不再执行后续的中间件和handler
+	Abort()
+	IsAborted() bool
+	// 写入状态码code并终止中间件链
+	AbortWithStatus(code int)
+	// 将obj编码为json以状态码code写入响应并终止中间件链, 编码失败时只写入状态码并通过Error记录错误
+	AbortWithStatusJSON(code int, obj interface{})
+	// 返回响应的状态码, 尚未写入时返回200
+	StatusCode() int
+	// 返回已写入的响应体字节数
+	WrittenBytes() int
+	// 返回当前Context的快照, 用于在handler返回后仍会使用Context的goroutine中, 快照的Next不执行任何中间件,
+	// 快照的GetResp直接写入底层响应, 不经过中间件通过SetResp设置的包装
+	Copy() Context
+	// 接管底层连接, 响应不支持http.Hijacker时返回错误
+	Hijack() (net.Conn, *bufio.ReadWriter, error)
+	// 返回是否是WebSocket握手请求
+	IsWebsocket() bool
+	// 将已缓冲的响应数据发送给客户端, 响应不支持http.Flusher时不做任何操作
+	Flush()
+	// 写入一个Server-Sent Events事件并立即发送给客户端, 非string类型的data会编码为json,
+	// 客户端断开连接后返回GetReq().Context().Err(), handler可据此退出循环
+	SSEvent(name string, data interface{}) error
+	SetCookie(cookie *http.Cookie)
+	// 返回请求中名为name的cookie的原始值, 不存在时返回http.ErrNoCookie
+	Cookie(name string) (string, error)
+	// 设置url编码value后的cookie, 需通过SimpleCookie读取
+	SetSimpleCookie(name, value string, maxAge int, path string, secure, httpOnly bool)
+	// 返回通过SetSimpleCookie设置的名为name的cookie解码后的值, 不存在时返回http.ErrNoCookie
+	SimpleCookie(name string) (string, error)
+	// 返回请求头key的值
+	GetHeader(key string) string
+	// 设置响应头
+	SetHeader(key, value string)
+	// 追加响应头
+	AddHeader(key, value string)
+	// 返回请求的Content-Type中的媒体类型, 不含参数
+	ContentType() string
+	// 将文件作为响应返回, 支持Range及缓存相关的请求头, 文件不存在时响应404
+	File(filepath string)
+	// 同File, 同时设置Content-Disposition使浏览器以filename下载该文件
+	FileAttachment(filepath, filename string)
+	// 返回multipart表单中名为name的第一个文件
+	FormFile(name string) (*multipart.FileHeader, error)
+	// 返回解析后的multipart表单
+	MultipartForm() (*multipart.Form, error)
+	// 将上传的文件保存到dst, dst所在目录不存在时自动创建
+	SaveUploadedFile(fh *multipart.FileHeader, dst string) error
+	// 读取完整的请求体, 读取后请求体被替换为读取到的内容, 因此之后仍可再次读取
+	GetRawData() ([]byte, error)
+	// 根据请求的Accept头部从offered中选取最合适的媒体类型, 均不可接受时返回空串, 请求不含Accept时返回offered[0]
+	NegotiateFormat(offered ...string) string
+	// 根据NegotiateFormat选取的媒体类型将data编码为JSON或XML后写入响应, 无可接受的类型时响应406并返回错误
+	Negotiate(code int, data interface{}, offered ...string) error
+	// 使用data执行名称为name的模板并以text/html写入响应, 执行失败时不写入任何内容并返回错误
+	HTML(code int, name string, data interface{}) error
+	// 将json格式的请求体解析到obj
+	BindJSON(obj interface{}) error
+	// 将查询参数按字段的query标签解析到obj指向的结构体, 未设置标签时使用字段名
+	BindQuery(obj interface{}) error
+	// 将请求体中的表单(含multipart表单)按字段的form标签解析到obj指向的结构体, 未设置标签时使用字段名
+	BindForm(obj interface{}) error
+	// 将路径参数按字段的uri标签解析到obj指向的结构体, 未设置标签时使用字段名
+	BindUri(obj interface{}) error
+	// 记录处理请求过程中产生的错误并返回err, err为nil时不记录
+	Error(err error) error
+	// 返回通过Error记录的所有错误
+	Errors() []error
+	// 保存只在本次请求中有效的键值对
+	Set(key string, value interface{})
+	// 返回通过Set保存的值
+	Get(key string) (interface{}, bool)
+	// 返回CSRFMiddleware为本次请求生成或校验通过的token, 未使用CSRFMiddleware时返回空串
+	CSRFToken() string
+	// 返回客户端IP, 依次取X-Forwarded-For的第一个地址、X-Real-IP、RemoteAddr中的host
+	// 注意: 请求头可被客户端伪造, 仅在服务部署于可信代理之后时使用
+	ClientIP() string
 }
 
 func New() Engine {
 	return &engine{
-		r: router.New(),
+		r:                 router.New(),
+		requestLogging:    true,
+		requestLog:        DefaultRequestLogConfig(),
+		logger:            LogsAdapter{},
+		logIdHeader:       string(logs.LogIdContextKey),
+		emptyPathRedirect: "/",
+		indexPath:         "/index",
+		shutdownTimeout:   10 * time.Second,
+		genLogId:          logs.GenLogId,
+		correlationIdHeaders: []string{
+			"X-Request-ID",
+			"X-Correlation-ID",
+		},
+		ctxPool: sync.Pool{
+			New: func() interface{} {
+				return &reqContext{}
+			},
+		},
+		writerPool: sync.Pool{
+			New: func() interface{} {
+				return &responseWriter{}
+			},
+		},
 	}
 }
 
+// Any、Mount、Proxy注册的请求方法, 不包含CONNECT和TRACE
+var anyMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
 type engine struct {
-	r              router.Router
-	allowedMethods struct {
-		s   []string
-		str string
-	}
+	// 保护r、routes、allowedMethods、allowHeader, 使注册路由与处理请求可以并发进行
+	routeMu sync.RWMutex
+	r       router.Router
+	// 已注册路由的所有方法, 按首次注册的顺序排列
+	allowedMethods []string
+	// 405响应的Allow头部, 为按字典序排列并以','连接的allowedMethods, 仅在allowedMethods变化时重新生成
+	allowHeader          string
+	requestLogging       bool
+	logSkipPaths         map[string]struct{}
+	requestLog           RequestLogConfig
+	logger               Logger
+	caseInsensitive      bool
+	cleanPath            bool
+	redirectFixedPath    bool
+	logIdHeader          string
+	genLogId             func() string
+	correlationIdHeaders []string
+	routes               map[string]*routerValue // key为routeKey(method, path)
+	// 全局中间件, 值为[]middleware, 修改时在middlewareMu保护下整体替换, 以保证处理请求时无需加锁且请求中持有的快照不变
+	middlewares       atomic.Value
+	middlewareMu      sync.Mutex
+	ctxPool           sync.Pool // 复用*reqContext, 请求处理结束后Context即被回收, 需在其他goroutine中使用时应调用Context.Copy
+	writerPool        sync.Pool // 复用*responseWriter
+	baseCtx           context.Context
+	validator         func(obj interface{}) error
+	duplicatePolicy   DuplicatePolicy
+	errorHandler      func(c Context, err error)
+	htmlTemplate      *template.Template
+	emptyPathRedirect string
+	indexPath         string
+	noRoute           func(c Context)
+	serverTiming      bool
+	routeCache        *routeCache
+	lifecycleMu       sync.Mutex
+	servers           []*http.Server
+	startupHooks      []func()
+	shutdownHooks     []func(ctx context.Context) error
+	shutdownTimeout   time.Duration
+	concurrencySem    chan struct{}
+	concurrencyWait   time.Duration
+	// 不为nil时对POST请求按SetMethodOverride改写方法
+	overrideMethods map[string]struct{}
+	// 通过Subrouter创建时为创建它的Engine及路径前缀
+	parent *engine
+	prefix string
+}
+
+// DuplicatePolicy 重复注册路由时的处理策略
+type DuplicatePolicy int
+
+const (
+	// panic并给出重复的路由
+	PanicOnDuplicate DuplicatePolicy = iota
+	// 打印警告日志并忽略后注册的路由
+	WarnOnDuplicate
+	// 使用后注册的路由覆盖已注册的路由
+	OverwriteOnDuplicate
+)
+
+// MiddlewareInfo 全局中间件的信息
+type MiddlewareInfo struct {
+	Name    string
+	Handler func(c Context)
+}
+
+type middleware struct {
+	name    string
+	handler func(c Context)
 }
 
 type routerValue struct {
 	middlewares []func(c Context)
+	handler     func(c Context)
 	matchPath   string
+	name        string
+	meta        *RouteMetadata
+	converters  map[string]ParamConverter
 }
 
 func (e *engine) Register(node Node) {
-	node.Middlewares = append(node.Middlewares, node.Handler)
+	if e.parent != nil {
+		e.registerToParent(node)
+		return
+	}
+	e.routeMu.Lock()
+	defer e.routeMu.Unlock()
+	if e.routeCache != nil {
+		e.routeCache.purge()
+	}
+	// 拷贝一份避免修改调用方传入的切片
+	node.Middlewares = append(append(make([]func(c Context), 0, len(node.Middlewares)+1), node.Middlewares...), node.Handler)
 	for _, v := range node.Middlewares {
 		if v == nil {
 			panic("middleware or handle of a node is nil")
 		}
 	}
+	checkConverters(node.Path, node.Converters)
 
-	e.r.Register(node.Method, node.Path, &routerValue{
+	value := &routerValue{
 		middlewares: node.Middlewares,
+		handler:     node.Handler,
 		matchPath:   node.Path,
-	})
-	for _, v := range e.allowedMethods.s {
+		name:        node.Name,
+		meta:        node.Meta,
+		converters:  node.Converters,
+	}
+	key := routeKey(node.Method, node.Path)
+	if old, ok := e.routes[key]; ok {
+		switch e.duplicatePolicy {
+		case WarnOnDuplicate:
+			e.logger.Warn(context.Background(), "[EasyServer] duplicate route ignored: %s", key)
+		case OverwriteOnDuplicate:
+			// 路由树中保存的是old, 原地替换即可覆盖
+			*old = *value
+		default:
+			panic("duplicate route: " + key)
+		}
+		return
+	}
+	e.r.Register(node.Method, node.Path, value)
+	if e.routes == nil {
+		e.routes = make(map[string]*routerValue)
+	}
+	e.routes[key] = value
+	for _, v := range e.allowedMethods {
 		if v == node.Method {
 			return
 		}
 	}
-	e.allowedMethods.s = append(e.allowedMethods.s, node.Method)
-	sort.Strings(e.allowedMethods.s)
-	e.allowedMethods.str = strings.Join(e.allowedMethods.s, ",")
+	e.allowedMethods = append(e.allowedMethods, node.Method)
+	methods := append(make([]string, 0, len(e.allowedMethods)), e.allowedMethods...)
+	sort.Strings(methods)
+	e.allowHeader = strings.Join(methods, ",")
+}
+
+func (e *engine) RegisterWithMiddlewares(method, path string, handler func(c Context), mws ...func(c Context)) {
+	e.Register(Node{
+		Method:      method,
+		Path:        path,
+		Middlewares: mws,
+		Handler:     handler,
+	})
+}
+
+func (e *engine) RegisterRoutes(nodes []Node) {
+	for _, v := range nodes {
+		e.Register(v)
+	}
+}
+
+func (e *engine) MustRegisterRoutes(nodes []Node) {
+	if e.parent != nil {
+		// 子路由不保存路由, 需由父Engine以添加前缀后的路径检查
+		prefixed := make([]Node, 0, len(nodes))
+		for _, v := range nodes {
+			prefixed = append(prefixed, e.toParentNode(v))
+		}
+		e.parent.MustRegisterRoutes(prefixed)
+		return
+	}
+	keys := make(map[string]struct{}, len(nodes))
+	e.routeMu.RLock()
+	for _, v := range nodes {
+		key := routeKey(v.Method, v.Path)
+		_, registered := e.routes[key]
+		_, repeated := keys[key]
+		if registered || repeated {
+			e.routeMu.RUnlock()
+			panic("duplicate route: " + key)
+		}
+		keys[key] = struct{}{}
+	}
+	e.routeMu.RUnlock()
+	e.RegisterRoutes(nodes)
+}
+
+func (e *engine) SetDuplicateRoutePolicy(policy DuplicatePolicy) {
+	e.mustBeRoot("SetDuplicateRoutePolicy")
+	e.duplicatePolicy = policy
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
 }
 
 func (e *engine) RegisterGroup(group Group) {
@@ -89,118 +530,645 @@ func (e *engine) RegisterGroup(group Group) {
 	}
 }
 
+// APIVersionKey APIVersion返回的Group通过Context.Set保存版本号使用的key
+const APIVersionKey = "api_version"
+
+func (e *engine) APIVersion(version, prefix string) Group {
+	version = strings.Trim(version, "/")
+	if version == "" {
+		panic("api version must not be empty")
+	}
+	return Group{
+		RootPath: strings.TrimSuffix(prefix, "/") + "/" + version,
+		Middlewares: []func(c Context){
+			func(c Context) {
+				c.Set(APIVersionKey, version)
+				c.Next()
+			},
+		},
+	}
+}
+
+func (e *engine) AppendMiddleware(handler func(c Context)) {
+	e.AppendNamedMiddleware("", handler)
+}
+
+// 返回当前全局中间件的快照, 调用方不能修改返回的切片
+func (e *engine) globalMiddlewares() []middleware {
+	mws, _ := e.middlewares.Load().([]middleware)
+	return mws
+}
+
+func (e *engine) AppendNamedMiddleware(name string, handler func(c Context)) {
+	if handler == nil {
+		panic("middleware must not be nil")
+	}
+	e.middlewareMu.Lock()
+	defer e.middlewareMu.Unlock()
+	old := e.globalMiddlewares()
+	mws := make([]middleware, 0, len(old)+1)
+	mws = append(mws, old...)
+	e.middlewares.Store(append(mws, middleware{
+		name:    name,
+		handler: handler,
+	}))
+}
+
+func (e *engine) RemoveMiddleware(name string) bool {
+	if name == "" {
+		panic("middleware name must not be empty")
+	}
+	e.middlewareMu.Lock()
+	defer e.middlewareMu.Unlock()
+	old := e.globalMiddlewares()
+	for i, v := range old {
+		if v.name != name {
+			continue
+		}
+		mws := make([]middleware, 0, len(old)-1)
+		mws = append(mws, old[:i]...)
+		e.middlewares.Store(append(mws, old[i+1:]...))
+		return true
+	}
+	return false
+}
+
+func (e *engine) Middlewares() []MiddlewareInfo {
+	mws := e.globalMiddlewares()
+	ret := make([]MiddlewareInfo, 0, len(mws))
+	for _, v := range mws {
+		ret = append(ret, MiddlewareInfo{
+			Name:    v.name,
+			Handler: v.handler,
+		})
+	}
+	return ret
+}
+
+func (e *engine) UseIf(cond func(req *http.Request) bool, mw func(c Context)) {
+	if cond == nil || mw == nil {
+		panic("cond and middleware must not be nil")
+	}
+	e.AppendMiddleware(func(c Context) {
+		if !cond(c.GetReq()) {
+			c.Next()
+			return
+		}
+		mw(c)
+	})
+}
+
+func (e *engine) UseForMethods(methods []string, mw func(c Context)) {
+	set := make(map[string]struct{}, len(methods))
+	for _, v := range methods {
+		set[v] = struct{}{}
+	}
+	e.UseIf(func(req *http.Request) bool {
+		_, ok := set[req.Method]
+		return ok
+	}, mw)
+}
+
+func (e *engine) AppendMiddlewareFor(pathPrefix string, handler func(c Context)) {
+	e.UseIf(func(req *http.Request) bool {
+		// 与路由查找使用相同的路径, 避免通过改变大小写等方式绕过中间件
+		root := e.root()
+		prefix := pathPrefix
+		if root.caseInsensitive {
+			prefix = strings.ToLower(prefix)
+		}
+		return strings.HasPrefix(root.routingPath(req.URL.Path), prefix)
+	}, handler)
+}
+
+// 返回查找路由时使用的路径, 即按SetCleanPath清理并按SetCaseInsensitiveRouting转换为小写后的p
+func (e *engine) routingPath(p string) string {
+	if e.cleanPath {
+		p = cleanPath(p)
+	}
+	if e.caseInsensitive {
+		p = strings.ToLower(p)
+	}
+	return p
+}
+
+// pathSet 请求路径的集合, 与路由查找一样按处理请求的Engine的SetCleanPath、SetCaseInsensitiveRouting匹配请求路径,
+// 用于中间件的排除路径等, 避免通过改变大小写等方式绕过中间件
+type pathSet struct {
+	exact map[string]struct{}
+	lower map[string]struct{}
+}
+
+func newPathSet(paths []string) pathSet {
+	s := pathSet{
+		exact: make(map[string]struct{}, len(paths)),
+		lower: make(map[string]struct{}, len(paths)),
+	}
+	for _, v := range paths {
+		s.exact[v] = struct{}{}
+		s.lower[strings.ToLower(v)] = struct{}{}
+	}
+	return s
+}
+
+// has 返回c的请求路径是否在s中
+func (s pathSet) has(c Context) bool {
+	p := c.GetReq().URL.Path
+	set := s.exact
+	if rc, ok := c.(*reqContext); ok && rc.engine != nil {
+		root := rc.engine.root()
+		p = root.routingPath(p)
+		if root.caseInsensitive {
+			set = s.lower
+		}
+	}
+	_, ok := set[p]
+	return ok
+}
+
+// 返回处理请求的Engine, 子路由返回其最上层的父Engine
+func (e *engine) root() *engine {
+	for e.parent != nil {
+		e = e.parent
+	}
+	return e
+}
+
 func (e *engine) RunHttp(port int) error {
-	return http.ListenAndServe(":"+fmt.Sprintf("%d", port), e)
+	e.mustBeRoot("RunHttp")
+	return e.serve(":"+fmt.Sprintf("%d", port), "", "")
 }
 
 func (e *engine) RunHttps(port int, certFile, keyFile string) error {
-	return http.ListenAndServeTLS(":"+fmt.Sprintf("%d", port), certFile, keyFile, e)
+	e.mustBeRoot("RunHttps")
+	return e.serve(":"+fmt.Sprintf("%d", port), certFile, keyFile)
+}
+
+func (e *engine) SetRequestLogging(enable bool) {
+	e.mustBeRoot("SetRequestLogging")
+	e.requestLogging = enable
+}
+
+func (e *engine) SetLogSkipPaths(paths []string) {
+	e.mustBeRoot("SetLogSkipPaths")
+	e.logSkipPaths = make(map[string]struct{}, len(paths))
+	for _, v := range paths {
+		e.logSkipPaths[v] = struct{}{}
+	}
+}
+
+func (e *engine) SetEmptyPathRedirect(target string) {
+	e.mustBeRoot("SetEmptyPathRedirect")
+	e.emptyPathRedirect = target
+}
+
+func (e *engine) SetServerTimingHeader(enable bool) {
+	e.mustBeRoot("SetServerTimingHeader")
+	e.serverTiming = enable
+}
+
+func (e *engine) NoRoute(h func(c Context)) {
+	e.mustBeRoot("NoRoute")
+	e.noRoute = h
+}
+
+func (e *engine) SetIndexPath(path string) {
+	e.mustBeRoot("SetIndexPath")
+	e.indexPath = path
+}
+
+func (e *engine) SetLogIdHeader(name string) {
+	e.mustBeRoot("SetLogIdHeader")
+	if name == "" {
+		name = string(logs.LogIdContextKey)
+	}
+	e.logIdHeader = name
+}
+
+func (e *engine) SetLogIdGenerator(gen func() string) {
+	e.mustBeRoot("SetLogIdGenerator")
+	if gen == nil {
+		gen = logs.GenLogId
+	}
+	e.genLogId = gen
+}
+
+func (e *engine) SetCorrelationIdHeader(name string) {
+	e.mustBeRoot("SetCorrelationIdHeader")
+	if name == "" {
+		e.correlationIdHeaders = nil
+		return
+	}
+	e.correlationIdHeaders = []string{name}
+}
+
+// 返回请求携带的上游关联id, 不存在或不合法时返回空串
+func (e *engine) correlationId(req *http.Request) string {
+	for _, name := range e.correlationIdHeaders {
+		id := req.Header.Get(name)
+		if id == "" || len(id) > 128 {
+			continue
+		}
+		valid := true
+		for i := 0; i < len(id); i++ {
+			if id[i] <= ' ' || id[i] > '~' {
+				valid = false
+				break
+			}
+		}
+		if valid {
+			return id
+		}
+	}
+	return ""
+}
+
+// 返回是否需要打印path对应请求的trace日志
+func (e *engine) needRequestLog(path string) bool {
+	if !e.requestLogging {
+		return false
+	}
+	_, skip := e.logSkipPaths[path]
+	return !skip
+}
+
+func (e *engine) SetBaseContext(ctx context.Context) {
+	e.mustBeRoot("SetBaseContext")
+	e.baseCtx = ctx
+}
+
+// baseValueContext 的取消和超时取自请求的context, 值优先从请求的context中查找, 查找不到时再从base中查找
+type baseValueContext struct {
+	context.Context
+	base context.Context
+}
+
+func (c baseValueContext) Value(key interface{}) interface{} {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+	return c.base.Value(key)
+}
+
+func (e *engine) SetCaseInsensitiveRouting(enable bool) {
+	e.mustBeRoot("SetCaseInsensitiveRouting")
+	e.caseInsensitive = enable
+}
+
+func (e *engine) SetCleanPath(enable bool) {
+	e.mustBeRoot("SetCleanPath")
+	e.cleanPath = enable
+}
+
+func (e *engine) SetRedirectFixedPath(enable bool) {
+	e.mustBeRoot("SetRedirectFixedPath")
+	e.redirectFixedPath = enable
+}
+
+// 返回对p进行清理及修正静态部分的大小写后能找到method对应路由的路径, 路径参数的值保持不变, 找不到时返回空串
+func (e *engine) fixedPath(method, p string) string {
+	cleaned := cleanPath(p)
+	for _, v := range []string{cleaned, strings.ToLower(cleaned)} {
+		if e.caseInsensitive {
+			v = strings.ToLower(v)
+		}
+		value, _, _ := e.r.Lookup(method, v)
+		if value == nil {
+			continue
+		}
+		rv := value.(*routerValue)
+		fixed, ok := fixPathCase(rv.matchPath, cleaned)
+		if !ok || fixed == p {
+			continue
+		}
+		if _, ok := convertParams(rv.converters, extractParams(rv.matchPath, fixed)); ok {
+			return fixed
+		}
+	}
+	return ""
+}
+
+// fixPathCase 将p中与路由模式pattern的静态部分对应的字符替换为pattern中的字符, 路径参数原样保留,
+// 要求p或其小写形式能够匹配pattern
+func fixPathCase(pattern, p string) (string, bool) {
+	var b strings.Builder
+	i, j := 0, 0
+	for i < len(pattern) {
+		switch pattern[i] {
+		case '*':
+			b.WriteString(p[j:])
+			return b.String(), true
+		case ':':
+			for i < len(pattern) && pattern[i] != '/' {
+				i++
+			}
+			k := j
+			for k < len(p) && p[k] != '/' {
+				k++
+			}
+			b.WriteString(p[j:k])
+			j = k
+		default:
+			if j >= len(p) {
+				return "", false
+			}
+			r, n := utf8.DecodeRuneInString(pattern[i:])
+			pr, m := utf8.DecodeRuneInString(p[j:])
+			if r != pr && unicode.ToLower(r) != unicode.ToLower(pr) {
+				return "", false
+			}
+			b.WriteRune(r)
+			i += n
+			j += m
+		}
+	}
+	return b.String(), j == len(p)
+}
+
+// 返回p经过path.Clean后的路径, 保留p末尾的'/'
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	cleaned := path.Clean(p)
+	if p[len(p)-1] == '/' && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
 }
 
 func (e *engine) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
-	logId := logs.GenLogId()
-	req = req.WithContext(logs.CtxWithLogId(req.Context(), logId))
+	if e.parent != nil {
+		e.parent.ServeHTTP(resp, req)
+		return
+	}
+	if sem := e.concurrencySem; sem != nil {
+		if !e.acquireConcurrency(sem, req) {
+			http.Error(resp, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		defer func() { <-sem }()
+	}
+	if e.baseCtx != nil {
+		req = req.WithContext(baseValueContext{
+			Context: req.Context(),
+			base:    e.baseCtx,
+		})
+	}
+	// 请求由其他Engine转发而来(如Mount)时沿用已有的log id, 否则优先使用上游传入的关联id
+	logId := logs.GetLogId(req.Context())
+	if logId == "" {
+		if logId = e.correlationId(req); logId == "" {
+			logId = e.genLogId()
+		}
+		req = req.WithContext(logs.CtxWithLogId(req.Context(), logId))
+	}
+	if e.overrideMethods != nil {
+		_ = overrideMethod(req, e.overrideMethods, false)
+	}
+	// 响应头可能在handler写入响应体时即已发送, 需在执行中间件链之前设置
+	resp.Header().Set(e.logIdHeader, logId)
+	needLog := e.needRequestLog(req.URL.Path)
 	defer func() {
-		resp.Header().Set(string(logs.LogIdContextKey), logId)
-		logs.CtxTrace(req.Context(), "[EasyServer] Resp=%v", tostr.String(&struct {
+		if !needLog {
+			return
+		}
+		e.logger.Trace(req.Context(), "[EasyServer] Resp=%v", tostr.String(&struct {
 			Header interface{}
 		}{
 			Header: resp.Header(),
 		}))
 	}()
 
-	logs.CtxTrace(req.Context(), "[EasyServer] Req=%v", tostr.String(&struct {
-		Method           interface{}
-		URL              interface{}
-		Proto            interface{}
-		ProtoMajor       interface{}
-		ProtoMinor       interface{}
-		Header           interface{}
-		Host             interface{}
-		Form             interface{}
-		PostForm         interface{}
-		MultipartForm    interface{}
-		Trailer          interface{}
-		RemoteAddr       interface{}
-		RequestURI       interface{}
-		ContentLength    interface{}
-		TransferEncoding interface{}
-	}{
-		Method:           req.Method,
-		URL:              req.URL,
-		Proto:            req.Proto,
-		ProtoMajor:       req.ProtoMajor,
-		ProtoMinor:       req.ProtoMinor,
-		Header:           req.Header,
-		Host:             req.Host,
-		Form:             req.Form,
-		PostForm:         req.PostForm,
-		MultipartForm:    req.MultipartForm,
-		Trailer:          req.Trailer,
-		RemoteAddr:       req.RemoteAddr,
-		RequestURI:       req.RequestURI,
-		ContentLength:    req.ContentLength,
-		TransferEncoding: req.TransferEncoding,
-	}))
+	if needLog {
+		e.logRequest(req)
+	}
+
+	writer := e.writerPool.Get().(*responseWriter)
+	writer.Reset(resp)
+	c := e.ctxPool.Get().(*reqContext)
+	c.engine = e
+	c.req = req
+	c.resp = writer
+	c.writer = writer
+	c.globalMiddlewares = e.globalMiddlewares()
+	c.needLog = needLog
+	c.start = time.Now()
+	if e.serverTiming {
+		writer.serverTiming, writer.start = true, c.start
+	}
+	defer func() {
+		c.Reset()
+		e.ctxPool.Put(c)
+		writer.Reset(nil)
+		e.writerPool.Put(writer)
+	}()
+
+	defer func() {
+		if err := recover(); err != nil {
+			e.logger.Critical(req.Context(), "[EasyServer] panic in handler, err=%v, stack=\n%s", err, debug.Stack())
+		}
+	}()
+
+	c.Next()
+	// 未写入任何内容时由net/http在返回后写入200, 此时仍可设置响应头
+	if e.serverTiming && !writer.Written() {
+		writer.setServerTiming()
+	}
+}
+
+// 根据c中的请求查找路由并执行路由对应的中间件和handler, 未找到路由时返回false
+func (e *engine) dispatch(c *reqContext) bool {
+	req, resp := c.req, c.resp
+	if req.URL.Path == "" && e.emptyPathRedirect != "" {
+		req.URL.Path = e.emptyPathRedirect
+		http.Redirect(resp, req, req.URL.String(), http.StatusPermanentRedirect)
+		return false
+	}
+
+	if e.cleanPath {
+		if cleaned := cleanPath(req.URL.Path); cleaned != req.URL.Path {
+			req.URL.Path = cleaned
+			req.URL.RawPath = ""
+			http.Redirect(resp, req, req.URL.String(), http.StatusPermanentRedirect)
+			return false
+		}
+	}
+
+	lookupPath := e.routingPath(req.URL.Path)
+
+	e.routeMu.RLock()
+	h, urlParams, ok := e.route(c, lookupPath)
+	e.routeMu.RUnlock()
+	if !ok {
+		return false
+	}
+	if c.needLog {
+		e.logger.Trace(req.Context(), "[EasyServer] mathPath=%v, pathParam=%v", tostr.String(h.matchPath), tostr.String(urlParams))
+	}
+	c.pathParam = urlParams
+	c.middlewares = h.middlewares
+	c.handler = h.handler
+	c.handlerName = h.name
+	c.matchPath = h.matchPath
+	return c.Next()
+}
+
+// route 查找请求对应的路由, 找到时返回路由的副本, 否则写入404、405、重定向等响应并返回false, 调用方需持有routeMu的读锁
+func (e *engine) route(c *reqContext, lookupPath string) (routerValue, []router.UrlParam, bool) {
+	req, resp := c.req, c.resp
+	// 未注册OPTIONS路由时自动响应该路径支持的方法, 需要处理CORS预检请求时应在全局中间件中处理
+	if req.Method == http.MethodOptions {
+		if value, _, _ := e.r.Lookup(http.MethodOptions, lookupPath); value == nil {
+			if methods := e.methodsOf(lookupPath); len(methods) > 0 {
+				resp.Header().Set("Allow", strings.Join(methods, ", "))
+				resp.WriteHeader(http.StatusOK)
+				return routerValue{}, nil, false
+			}
+		}
+	}
 
 	methodRegister := false
-	for _, v := range e.allowedMethods.s {
-		if v == req.Method {
+	for _, v := range e.allowedMethods {
+		// 注册了GET路由时自动支持HEAD
+		if v == req.Method || (req.Method == http.MethodHead && v == http.MethodGet) {
 			methodRegister = true
 			break
 		}
 	}
 	if !methodRegister {
-		resp.Header().Set("Allow", e.allowedMethods.str)
+		resp.Header().Set("Allow", e.allowHeader)
 		http.Error(resp, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
-		return
+		return routerValue{}, nil, false
 	}
 
-	if req.URL.Path == "" {
-		req.URL.Path = "/"
-		http.Redirect(resp, req, req.URL.String(), http.StatusPermanentRedirect)
-		return
+	var (
+		value        *routerValue
+		urlParams    []router.UrlParam
+		redirect     bool
+		headFallback bool
+	)
+	if entry, ok := e.routeCache.get(req.Method, lookupPath); ok {
+		value, urlParams, headFallback = entry.value, entry.params(req.URL.Path), entry.headFallback
+	} else {
+		var v interface{}
+		v, urlParams, redirect = e.r.Lookup(req.Method, lookupPath)
+		if v == nil && req.Method == http.MethodHead {
+			// 未注册HEAD路由时使用GET路由处理并丢弃响应体
+			v, urlParams, redirect = e.r.Lookup(http.MethodGet, lookupPath)
+			headFallback = v != nil
+		}
+		if v != nil {
+			value = v.(*routerValue)
+			e.routeCache.add(req.Method, lookupPath, value, headFallback)
+			// 忽略大小写时按转换为小写的路径匹配, 路径参数需从原始路径中取得
+			if lookupPath != req.URL.Path {
+				urlParams = extractParams(value.matchPath, req.URL.Path)
+			}
+		}
 	}
-
-	value, urlParams, redirect := e.r.Lookup(req.Method, req.URL.Path)
 	if value != nil {
-		h := value.(*routerValue)
-		defer func() {
-			if err := recover(); err != nil {
-				logs.CtxCritical(req.Context(), "[EasyServer] panic in handler, err=%v, stack=\n%s", err, debug.Stack())
+		// 路径参数转换失败时视为未匹配路由
+		if params, ok := convertParams(value.converters, urlParams); ok {
+			if headFallback {
+				c.resp = &headResponseWriter{ResponseWriter: c.resp}
 			}
-		}()
-		logs.CtxTrace(req.Context(), "[EasyServer] mathPath=%v, pathParam=%v", tostr.String(h.matchPath), tostr.String(urlParams))
-		(&reqContext{
-			req:         req,
-			resp:        resp,
-			pathParam:   urlParams,
-			middlewares: h.middlewares,
-			matchPath:   h.matchPath,
-		}).Next()
-		return
+			c.params = params
+			return *value, urlParams, true
+		}
 	}
 
 	if !redirect {
+		if e.redirectFixedPath {
+			if fixed := e.fixedPath(req.Method, req.URL.Path); fixed != "" {
+				code := http.StatusPermanentRedirect
+				if req.Method == http.MethodGet {
+					code = http.StatusMovedPermanently
+				}
+				req.URL.Path = fixed
+				req.URL.RawPath = ""
+				http.Redirect(resp, req, req.URL.String(), code)
+				return routerValue{}, nil, false
+			}
+		}
+		if lookupPath == "/" && e.indexPath != "" && e.indexPath != "/" {
+			req.URL.Path = e.indexPath
+			req.URL.RawPath = ""
+			http.Redirect(resp, req, req.URL.String(), http.StatusFound)
+			return routerValue{}, nil, false
+		}
+		if e.noRoute != nil {
+			return routerValue{
+				middlewares: []func(c Context){e.noRoute},
+				handler:     e.noRoute,
+			}, nil, true
+		}
 		http.NotFound(resp, req)
-		return
+		return routerValue{}, nil, false
 	}
 
-	if req.URL.Path[len(req.URL.Path)-1] == '/' {
-		req.URL.Path = req.URL.Path[:len(req.URL.Path)-1]
+	// 基于原始路径重定向, 以保留路径参数的大小写
+	if p := req.URL.Path; p[len(p)-1] == '/' {
+		req.URL.Path = p[:len(p)-1]
 	} else {
-		req.URL.Path += "/"
+		req.URL.Path = p + "/"
 	}
+	req.URL.RawPath = ""
 	http.Redirect(resp, req, req.URL.String(), http.StatusPermanentRedirect)
+	return routerValue{}, nil, false
+}
+
+// 返回path注册了路由的所有方法, 结果包含OPTIONS且按字典序排列, path未注册任何路由时返回nil
+func (e *engine) methodsOf(path string) []string {
+	var methods []string
+	hasGet, hasHead := false, false
+	for _, v := range e.allowedMethods {
+		if v == http.MethodOptions {
+			continue
+		}
+		if value, _, _ := e.r.Lookup(v, path); value != nil {
+			methods = append(methods, v)
+			hasGet = hasGet || v == http.MethodGet
+			hasHead = hasHead || v == http.MethodHead
+		}
+	}
+	if hasGet && !hasHead {
+		methods = append(methods, http.MethodHead)
+	}
+	if len(methods) == 0 {
+		return nil
+	}
+	methods = append(methods, http.MethodOptions)
+	sort.Strings(methods)
+	return methods
 }
 
 type reqContext struct {
-	req         *http.Request
-	resp        http.ResponseWriter
-	pathParam   []router.UrlParam
-	middlewares []func(c Context)
-	curMW       int
-	matchPath   string
+	engine            *engine
+	req               *http.Request
+	resp              http.ResponseWriter
+	writer            *responseWriter
+	pathParam         []router.UrlParam
+	globalMiddlewares []middleware
+	curGlobalMW       int
+	routed            bool
+	middlewares       []func(c Context)
+	handler           func(c Context)
+	handlerName       string // 注册路由时记录的名称, 为空时HandlerName通过反射获取
+	curMW             int
+	matchPath         string
+	aborted           bool
+	needLog           bool
+	sseStarted        bool
+	errs              []error
+	keys              map[string]interface{}
+	start             time.Time              // ServeHTTP开始处理请求的时间
+	params            map[string]interface{} // 经ParamConverter转换后的路径参数
+}
+
+// Reset 清空c的所有字段以便放回对象池
+func (c *reqContext) Reset() {
+	*c = reqContext{}
 }
 
 func (c *reqContext) GetReq() *http.Request {
@@ -211,6 +1179,18 @@ func (c *reqContext) GetResp() http.ResponseWriter {
 	return c.resp
 }
 
+func (c *reqContext) SetReq(req *http.Request) {
+	c.req = req
+}
+
+func (c *reqContext) SetRequestContext(ctx context.Context) {
+	c.req = c.req.WithContext(ctx)
+}
+
+func (c *reqContext) SetResp(resp http.ResponseWriter) {
+	c.resp = resp
+}
+
 func (c *reqContext) GetParamParam() []router.UrlParam {
 	return c.pathParam
 }
@@ -219,12 +1199,235 @@ func (c *reqContext) GetMatchPath() string {
 	return c.matchPath
 }
 
+func (c *reqContext) FullPath() string {
+	return c.matchPath
+}
+
+// 返回注册路由时的Node.Name, 未设置时返回注册的handler的函数全名
+func (c *reqContext) HandlerName() string {
+	if c.handlerName != "" {
+		return c.handlerName
+	}
+	if c.handler == nil {
+		return ""
+	}
+	return funcName(c.handler)
+}
+
+// funcName 返回函数f的全名
+func funcName(f interface{}) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
+
+// httpHandlerName 返回h的名称, h为http.HandlerFunc时为函数全名, 否则为h的类型
+func httpHandlerName(h http.Handler) string {
+	if f, ok := h.(http.HandlerFunc); ok {
+		return funcName(f)
+	}
+	return fmt.Sprintf("%T", h)
+}
+
 // 返回true表示存在下一个中间件
+// 全局中间件在路由查找之前执行, 全部执行完后才会查找路由并执行路由对应的中间件和handler
 func (c *reqContext) Next() bool {
+	if c.aborted {
+		return false
+	}
+	if c.curGlobalMW < len(c.globalMiddlewares) {
+		c.curGlobalMW++
+		mw := c.globalMiddlewares[c.curGlobalMW-1]
+		defer annotatePanic(PanicInGlobalMiddleware, c.curGlobalMW-1, mw.name)
+		mw.handler(c)
+		return true
+	}
+	if !c.routed {
+		c.routed = true
+		return c.engine.dispatch(c)
+	}
 	if c.curMW >= len(c.middlewares) {
 		return false
 	}
 	c.curMW++
+	if c.curMW == len(c.middlewares) {
+		defer annotatePanic(PanicInHandler, c.curMW-1, "")
+	} else {
+		defer annotatePanic(PanicInRouteMiddleware, c.curMW-1, "")
+	}
 	c.middlewares[c.curMW-1](c)
 	return true
 }
+
+func (c *reqContext) Abort() {
+	c.aborted = true
+}
+
+func (c *reqContext) IsAborted() bool {
+	return c.aborted
+}
+
+func (c *reqContext) AbortWithStatus(code int) {
+	c.resp.WriteHeader(code)
+	c.Abort()
+}
+
+func (c *reqContext) AbortWithStatusJSON(code int, obj interface{}) {
+	c.Abort()
+	b, err := json.Marshal(obj)
+	if err != nil {
+		_ = c.Error(err)
+		c.resp.WriteHeader(code)
+		return
+	}
+	if err = c.render(code, MIMEJSON+"; charset=utf-8", b); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+func (c *reqContext) StatusCode() int {
+	return c.writer.StatusCode()
+}
+
+func (c *reqContext) WrittenBytes() int {
+	return c.writer.size
+}
+
+func (c *reqContext) Copy() Context {
+	pathParam := make([]router.UrlParam, 0, len(c.pathParam))
+	for _, v := range c.pathParam {
+		pathParam = append(pathParam, router.UrlParam{
+			Key:   append([]byte(nil), v.Key...),
+			Value: append([]byte(nil), v.Value...),
+		})
+	}
+	var keys map[string]interface{}
+	if c.keys != nil {
+		keys = make(map[string]interface{}, len(c.keys))
+		for k, v := range c.keys {
+			keys[k] = v
+		}
+	}
+	// c.writer会被回收复用, 快照持有其副本, 且不能经由中间件通过SetResp设置的包装间接引用c.writer,
+	// 因此快照直接写入底层响应, 仅保留HEAD请求丢弃响应体的包装
+	writer := &responseWriter{}
+	*writer = *c.writer
+	var resp http.ResponseWriter = writer
+	for w := c.resp; w != nil; {
+		if h, ok := w.(*headResponseWriter); ok {
+			resp = &headResponseWriter{ResponseWriter: writer, wroteHeader: h.wroteHeader}
+			break
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		w = u.Unwrap()
+	}
+	return &reqContext{
+		engine:      c.engine,
+		req:         c.req.WithContext(c.req.Context()),
+		resp:        resp,
+		writer:      writer,
+		pathParam:   pathParam,
+		routed:      true,
+		handler:     c.handler,
+		handlerName: c.handlerName,
+		matchPath:   c.matchPath,
+		aborted:     c.aborted,
+		needLog:     c.needLog,
+		errs:        append([]error(nil), c.errs...),
+		keys:        keys,
+		start:       c.start,
+		// 每个请求新建且之后不再修改, 可直接共享
+		params: c.params,
+	}
+}
+
+func (c *reqContext) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := c.resp.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the ResponseWriter doesn't support the Hijacker interface")
+	}
+	return h.Hijack()
+}
+
+func (c *reqContext) IsWebsocket() bool {
+	return headerContainsToken(c.req.Header, "Connection", "upgrade") &&
+		strings.EqualFold(strings.TrimSpace(c.req.Header.Get("Upgrade")), "websocket")
+}
+
+// 返回header中名为key的头部是否包含token(忽略大小写), 头部的值为逗号分隔的列表
+func headerContainsToken(header http.Header, key, token string) bool {
+	for _, v := range header.Values(key) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *reqContext) Flush() {
+	if f, ok := c.resp.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (c *reqContext) Deadline() (time.Time, bool) {
+	return c.req.Context().Deadline()
+}
+
+func (c *reqContext) Done() <-chan struct{} {
+	return c.req.Context().Done()
+}
+
+func (c *reqContext) Err() error {
+	return c.req.Context().Err()
+}
+
+// key为string类型且通过Set保存过时返回保存的值, 否则委托给GetReq().Context()
+func (c *reqContext) Value(key interface{}) interface{} {
+	if k, ok := key.(string); ok {
+		if v, ok := c.keys[k]; ok {
+			return v
+		}
+	}
+	return c.req.Context().Value(key)
+}
+
+func (c *reqContext) GetRawData() ([]byte, error) {
+	if c.req.Body == nil || c.req.Body == http.NoBody {
+		return nil, nil
+	}
+	// 请求体被http.MaxBytesReader等限制了大小时超出限制会返回错误
+	b, err := io.ReadAll(c.req.Body)
+	_ = c.req.Body.Close()
+	c.req.Body = io.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *reqContext) Error(err error) error {
+	if err != nil {
+		c.errs = append(c.errs, err)
+	}
+	return err
+}
+
+func (c *reqContext) Errors() []error {
+	return c.errs
+}
+
+func (c *reqContext) Set(key string, value interface{}) {
+	if c.keys == nil {
+		c.keys = make(map[string]interface{})
+	}
+	c.keys[key] = value
+}
+
+func (c *reqContext) Get(key string) (interface{}, bool) {
+	v, ok := c.keys[key]
+	return v, ok
+}
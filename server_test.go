@@ -0,0 +1,236 @@
+package easyserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gogokit/logs"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Trace(ctx context.Context, msg string, args ...interface{})    {}
+func (nopLogger) Debug(ctx context.Context, msg string, args ...interface{})    {}
+func (nopLogger) Info(ctx context.Context, msg string, args ...interface{})     {}
+func (nopLogger) Warn(ctx context.Context, msg string, args ...interface{})     {}
+func (nopLogger) Error(ctx context.Context, msg string, args ...interface{})    {}
+func (nopLogger) Critical(ctx context.Context, msg string, args ...interface{}) {}
+
+// 返回关闭了请求日志且不输出任何日志的engine
+func newTestEngine() *engine {
+	e := New().(*engine)
+	e.SetRequestLogging(false)
+	e.SetLogger(nopLogger{})
+	return e
+}
+
+// BenchmarkServeHTTP 测试不同数量的全局中间件下处理一个GET请求的耗时及分配
+func BenchmarkServeHTTP(b *testing.B) {
+	for _, n := range []int{0, 1, 5, 10} {
+		b.Run(fmt.Sprintf("middlewares=%d", n), func(b *testing.B) {
+			e := newTestEngine()
+			for i := 0; i < n; i++ {
+				e.AppendMiddleware(func(c Context) { c.Next() })
+			}
+			e.GET("/users/:id", func(c Context) {})
+			req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+			w := httptest.NewRecorder()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				e.ServeHTTP(w, req)
+			}
+		})
+	}
+}
+
+func BenchmarkGenLogId(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = logs.GenLogId()
+	}
+}
+
+// 防止基准测试中的分配被编译器优化掉
+var benchSink *reqContext
+
+// BenchmarkContextPooled 与BenchmarkContextAllocated对比使用sync.Pool复用Context前后每个请求的分配
+func BenchmarkContextPooled(b *testing.B) {
+	e := newTestEngine()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		writer := e.writerPool.Get().(*responseWriter)
+		writer.Reset(w)
+		c := e.ctxPool.Get().(*reqContext)
+		c.engine, c.req, c.resp, c.writer = e, req, writer, writer
+		benchSink = c
+		c.Reset()
+		e.ctxPool.Put(c)
+		writer.Reset(nil)
+		e.writerPool.Put(writer)
+	}
+}
+
+func BenchmarkContextAllocated(b *testing.B) {
+	e := newTestEngine()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		writer := &responseWriter{}
+		writer.Reset(w)
+		benchSink = &reqContext{engine: e, req: req, resp: writer, writer: writer}
+	}
+}
+
+// 需使用go test -race运行以检查注册路由与处理请求之间的数据竞争
+func TestRegisterWhileServing(t *testing.T) {
+	e := newTestEngine()
+	e.GET("/static", func(c Context) {})
+
+	const n = 200
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			e.GET(fmt.Sprintf("/dynamic/%d", i), func(c Context) {})
+			e.POST(fmt.Sprintf("/dynamic/%d", i), func(c Context) {})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			for _, path := range []string{"/static", fmt.Sprintf("/dynamic/%d", i)} {
+				w := httptest.NewRecorder()
+				e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+				if w.Code != http.StatusOK && w.Code != http.StatusNotFound {
+					t.Errorf("GET %s: unexpected status %d", path, w.Code)
+				}
+			}
+		}
+	}()
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/dynamic/%d", i), nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("POST /dynamic/%d: expected 200 after registration, got %d", i, w.Code)
+		}
+	}
+}
+
+func BenchmarkRegister1000Routes(b *testing.B) {
+	methods := []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete}
+	paths := make([]string, 1000)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("/api/v1/resource%d/:id", i/len(methods))
+	}
+	handler := func(c Context) {}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e := newTestEngine()
+		for j, path := range paths {
+			e.RegisterWithMiddlewares(methods[j%len(methods)], path, handler)
+		}
+	}
+}
+
+func TestIndexPathRedirect(t *testing.T) {
+	e := newTestEngine()
+	e.GET("/index", func(c Context) {})
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?a=1", nil))
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/index?a=1" {
+		t.Fatalf("GET /: expected 302 to /index?a=1, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	// 路径为空的请求先重定向到"/"
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.URL.Path = ""
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	if w.Code != http.StatusPermanentRedirect || w.Header().Get("Location") != "/" {
+		t.Fatalf("GET with empty path: expected 308 to /, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	e.SetIndexPath("/home")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/home" {
+		t.Fatalf("GET /: expected 302 to /home, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	e.SetIndexPath("")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("GET / with index path disabled: expected 404, got %d", w.Code)
+	}
+}
+
+// 响应头在写入响应体时即已发送, log id需在此之前设置
+func TestLogIdHeaderSentWithBody(t *testing.T) {
+	e := newTestEngine()
+	e.SetLogIdHeader("X-Request-ID")
+	e.GET("/body", func(c Context) {
+		_, _ = c.GetResp().Write([]byte("hello"))
+	})
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/body", nil))
+	if w.Result().Header.Get("X-Request-ID") == "" {
+		t.Fatal("expected a generated X-Request-ID on a response with a body")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/body", nil)
+	req.Header.Set("X-Request-ID", "upstream-id")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	if got := w.Result().Header.Get("X-Request-ID"); got != "upstream-id" {
+		t.Fatalf("expected the correlation id to be echoed, got %q", got)
+	}
+}
+
+func namedTestHandler(c Context) error { return nil }
+
+func namedTestHTTPHandler(w http.ResponseWriter, req *http.Request) {}
+
+func TestHandlerNameOfWrappedHandlers(t *testing.T) {
+	e := newTestEngine()
+	var got string
+	e.AppendMiddleware(func(c Context) {
+		c.Next()
+		got = c.HandlerName()
+	})
+	e.GETE("/e", namedTestHandler)
+	e.Handle(http.MethodGet, "/h", namedTestHTTPHandler)
+	e.Register(Node{Method: http.MethodGet, Path: "/n", Name: "users.list", Handler: WrapH(http.NotFoundHandler())})
+	e.Health("/health")
+	e.GET("/plain", func(c Context) {})
+
+	cases := map[string]string{
+		"/e":      "github.com/gogokit/easyserver.namedTestHandler",
+		"/h":      "github.com/gogokit/easyserver.namedTestHTTPHandler",
+		"/n":      "users.list",
+		"/health": "easyserver.Health",
+		"/plain":  "github.com/gogokit/easyserver.TestHandlerNameOfWrappedHandlers.func2",
+	}
+	for path, want := range cases {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		if got != want {
+			t.Errorf("%s: expected handler name %q, got %q", path, want, got)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package easyserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SessionKey Session中间件通过Context.Set保存session数据使用的key
+const SessionKey = "session"
+
+// SessionStore session的存储
+type SessionStore interface {
+	// 返回id对应的session数据, session不存在时返回nil, nil
+	Get(id string) (map[string]interface{}, error)
+	// 保存id对应的session数据
+	Save(id string, data map[string]interface{}) error
+	// 生成新的session id
+	New() string
+}
+
+// Session 返回服务端session中间件: 根据名称为cookieName的cookie从store加载session数据,
+// 通过Context.Set(SessionKey, data)保存, data的类型为map[string]interface{}; 后续中间件及handler执行完成后将data保存到store.
+// cookie不存在或session已失效时创建新的session, 新session的data为空时不保存, 以免未使用session的请求占用存储.
+// maxAge为cookie的有效期, 小于等于0时为24小时, 应与store中session的有效期一致
+func Session(store SessionStore, cookieName string, maxAge time.Duration) func(c Context) {
+	if store == nil {
+		panic("session store must not be nil")
+	}
+	if maxAge <= 0 {
+		maxAge = 24 * time.Hour
+	}
+	return func(c Context) {
+		var data map[string]interface{}
+		id, err := c.Cookie(cookieName)
+		if err == nil && id != "" {
+			if data, err = store.Get(id); err != nil {
+				_ = c.Error(err)
+				c.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+		}
+		isNew := data == nil
+		if isNew {
+			id, data = store.New(), make(map[string]interface{})
+		}
+
+		// 响应可能在Next中写出, 需提前设置cookie
+		c.SetCookie(&http.Cookie{
+			Name:     cookieName,
+			Value:    id,
+			Path:     "/",
+			MaxAge:   int(maxAge / time.Second),
+			HttpOnly: true,
+			Secure:   c.GetReq().TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+		})
+		c.Set(SessionKey, data)
+		c.Next()
+
+		if isNew && len(data) == 0 {
+			return
+		}
+		if err := store.Save(id, data); err != nil {
+			_ = c.Error(err)
+			loggerOf(c).Error(c.GetReq().Context(), "[EasyServer] save session failed, err=%v", err)
+		}
+	}
+}
+
+// MemorySessionStore 基于内存的SessionStore, 进程重启后session丢失, 适用于测试及单实例的小型服务
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	sessions map[string]*memorySession
+	// 下次清理过期session的时间
+	nextSweep time.Time
+}
+
+type memorySession struct {
+	data     map[string]interface{}
+	expireAt time.Time
+}
+
+// NewMemorySessionStore 返回空的MemorySessionStore, session在最后一次保存ttl后过期, ttl小于等于0时为24小时
+func NewMemorySessionStore(ttl time.Duration) *MemorySessionStore {
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return &MemorySessionStore{
+		ttl:       ttl,
+		sessions:  make(map[string]*memorySession),
+		nextSweep: time.Now().Add(ttl),
+	}
+}
+
+func (s *MemorySessionStore) Get(id string) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	if time.Now().After(sess.expireAt) {
+		delete(s.sessions, id)
+		return nil, nil
+	}
+	return copySession(sess.data), nil
+}
+
+// Save 保存session并刷新其过期时间, 每隔ttl清理一次所有过期的session
+func (s *MemorySessionStore) Save(id string, data map[string]interface{}) error {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.After(s.nextSweep) {
+		for k, v := range s.sessions {
+			if now.After(v.expireAt) {
+				delete(s.sessions, k)
+			}
+		}
+		s.nextSweep = now.Add(s.ttl)
+	}
+	s.sessions[id] = &memorySession{data: copySession(data), expireAt: now.Add(s.ttl)}
+	return nil
+}
+
+func (s *MemorySessionStore) New() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func copySession(data map[string]interface{}) map[string]interface{} {
+	ret := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		ret[k] = v
+	}
+	return ret
+}
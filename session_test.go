@@ -0,0 +1,64 @@
+package easyserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSessionStoresOnlyUsedSessions(t *testing.T) {
+	store := NewMemorySessionStore(time.Hour)
+	e := newTestEngine()
+	e.AppendMiddleware(Session(store, "sid", time.Hour))
+	e.GET("/anonymous", func(c Context) {})
+	e.GET("/login", func(c Context) {
+		data, _ := c.Get(SessionKey)
+		data.(map[string]interface{})["user"] = "alice"
+	})
+	e.GET("/me", func(c Context) {
+		data, _ := c.Get(SessionKey)
+		_, _ = c.GetResp().Write([]byte(data.(map[string]interface{})["user"].(string)))
+	})
+
+	for i := 0; i < 100; i++ {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/anonymous", nil))
+	}
+	if n := len(store.sessions); n != 0 {
+		t.Fatalf("expected no stored sessions after anonymous requests, got %d", n)
+	}
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/login", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].MaxAge != 3600 {
+		t.Fatalf("expected one session cookie with MaxAge 3600, got %v", cookies)
+	}
+	if n := len(store.sessions); n != 1 {
+		t.Fatalf("expected 1 stored session, got %d", n)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	if w.Body.String() != "alice" {
+		t.Fatalf("expected the stored session to be loaded, got %q", w.Body.String())
+	}
+}
+
+func TestMemorySessionStoreExpires(t *testing.T) {
+	store := NewMemorySessionStore(20 * time.Millisecond)
+	_ = store.Save("a", map[string]interface{}{"k": 1})
+	_ = store.Save("b", map[string]interface{}{"k": 2})
+	time.Sleep(30 * time.Millisecond)
+
+	if data, _ := store.Get("a"); data != nil {
+		t.Fatalf("expected session a to expire, got %v", data)
+	}
+	// 保存新session时清理过期的session b
+	_ = store.Save("c", map[string]interface{}{"k": 3})
+	if _, ok := store.sessions["b"]; ok || len(store.sessions) != 1 {
+		t.Fatalf("expected expired sessions to be evicted, got %d sessions", len(store.sessions))
+	}
+}
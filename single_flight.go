@@ -0,0 +1,62 @@
+package easyserver
+
+import (
+	"net/http"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// SingleFlightMiddleware 返回合并相同并发请求的中间件: 仅对GET、HEAD、OPTIONS请求生效,
+// keyFunc返回相同key的请求同时只有一个执行后续的中间件及handler, 其余请求阻塞等待并共享其响应(Set-Cookie除外).
+// keyFunc为nil时使用method+请求URI, 且不合并带有Authorization或Cookie请求头的请求, 以免将一个用户的响应共享给其他用户;
+// 自定义keyFunc需自行区分不同用户的请求, 返回空串时不合并该请求. 执行中使用了Flush或Hijack的响应无法共享, 等待的请求会各自执行
+func SingleFlightMiddleware(keyFunc func(req *http.Request) string) func(c Context) {
+	if keyFunc == nil {
+		keyFunc = func(req *http.Request) string {
+			if req.Header.Get("Authorization") != "" || req.Header.Get("Cookie") != "" {
+				return ""
+			}
+			return req.Method + " " + req.URL.RequestURI()
+		}
+	}
+	var g singleflight.Group
+
+	return func(c Context) {
+		req := c.GetReq()
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			c.Next()
+			return
+		}
+		key := keyFunc(req)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		executed := false
+		v, _, _ := g.Do(key, func() (interface{}, error) {
+			executed = true
+			resp := c.GetResp()
+			w := &cacheWriter{ResponseWriter: resp}
+			c.SetResp(w)
+			defer c.SetResp(resp)
+			c.Next()
+			if w.header == nil || w.uncacheable {
+				return (*cachedResponse)(nil), nil
+			}
+			return &cachedResponse{Status: w.status, Header: w.header, Body: w.body}, nil
+		})
+		if executed {
+			return
+		}
+		shared := v.(*cachedResponse)
+		if shared == nil {
+			c.Next()
+			return
+		}
+		c.Abort()
+		shared.writeTo(c.GetResp(), req.Method == http.MethodHead)
+	}
+}
@@ -0,0 +1,22 @@
+package easyserver
+
+import "time"
+
+// SlowRequestMiddleware 返回检测慢请求的中间件, 后续中间件及handler的执行耗时超过threshold时调用handler,
+// handler为nil时以Warn级别记录日志
+func SlowRequestMiddleware(threshold time.Duration, handler func(c Context, elapsed time.Duration)) func(c Context) {
+	if handler == nil {
+		handler = func(c Context, elapsed time.Duration) {
+			req := c.GetReq()
+			loggerOf(c).Warn(req.Context(), "[EasyServer] slow request, method=%v, path=%v, matchPath=%v, status=%v, elapsed=%v",
+				req.Method, req.URL.Path, c.GetMatchPath(), c.StatusCode(), elapsed)
+		}
+	}
+	return func(c Context) {
+		start := time.Now()
+		c.Next()
+		if elapsed := time.Since(start); elapsed > threshold {
+			handler(c, elapsed)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package easyserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+func (c *reqContext) SSEvent(name string, data interface{}) error {
+	if err := c.req.Context().Err(); err != nil {
+		return err
+	}
+
+	if !c.sseStarted {
+		c.sseStarted = true
+		header := c.resp.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+	}
+
+	var payload string
+	switch v := data.(type) {
+	case string:
+		payload = v
+	case []byte:
+		payload = string(v)
+	default:
+		b, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		payload = string(b)
+	}
+
+	buf := bytes.Buffer{}
+	if name != "" {
+		buf.WriteString("event: ")
+		buf.WriteString(strings.NewReplacer("\r", "", "\n", "").Replace(name))
+		buf.WriteByte('\n')
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(payload, "\r\n", "\n"), "\n") {
+		buf.WriteString("data: ")
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+
+	if _, err := c.resp.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	c.Flush()
+	return nil
+}
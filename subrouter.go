@@ -0,0 +1,48 @@
+package easyserver
+
+import "strings"
+
+func (e *engine) Subrouter(prefix string) Engine {
+	if prefix == "" || prefix[0] != '/' {
+		panic("subrouter prefix must begin with '/'")
+	}
+	sub := New().(*engine)
+	sub.parent = e
+	sub.prefix = strings.TrimSuffix(prefix, "/")
+	sub.logger = e.logger
+	return sub
+}
+
+// registerToParent 为node添加前缀及子Engine的全局中间件后注册到parent
+func (e *engine) registerToParent(node Node) {
+	e.parent.Register(e.toParentNode(node))
+}
+
+// toParentNode 返回添加了前缀及子Engine的全局中间件的node
+func (e *engine) toParentNode(node Node) Node {
+	globals := e.globalMiddlewares()
+	mws := make([]func(c Context), 0, len(globals)+len(node.Middlewares))
+	for _, v := range globals {
+		mws = append(mws, v.handler)
+	}
+	node.Middlewares = append(mws, node.Middlewares...)
+	node.Path = e.prefix + node.Path
+	return node
+}
+
+// 子路由的请求由最上层的父Engine处理, 只对处理请求生效的设置需在父Engine上调用, 在子路由上调用时panic
+func (e *engine) mustBeRoot(method string) {
+	if e.parent != nil {
+		panic(method + " must be called on the root Engine instead of a subrouter")
+	}
+}
+
+// 返回e的路由使用的错误处理函数, 子路由未设置时使用父Engine的
+func (e *engine) errorHandlerOf() func(c Context, err error) {
+	for ; e != nil; e = e.parent {
+		if e.errorHandler != nil {
+			return e.errorHandler
+		}
+	}
+	return nil
+}
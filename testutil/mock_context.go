@@ -0,0 +1,68 @@
+package testutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogokit/easyserver"
+	"github.com/gogokit/router"
+)
+
+// MockContext 用于单独测试中间件的Context, 除Next和GetParamParam外的方法均为Engine中Context的真实实现,
+// 响应写入Recorder
+type MockContext struct {
+	easyserver.Context
+	Recorder *httptest.ResponseRecorder
+	// 不为nil时GetParamParam返回Params
+	Params []router.UrlParam
+	// 不为nil时Next调用NextFunc并返回其结果, 为nil时Next只记录调用并在未终止时返回true
+	NextFunc func() bool
+	// Next被调用的次数
+	NextCalls int
+}
+
+// NewMockContext 返回请求为method和path的MockContext
+func NewMockContext(method, path string) *MockContext {
+	return NewMockContextFromRequest(httptest.NewRequest(method, path, nil))
+}
+
+// NewMockContextFromRequest 返回请求为req的MockContext
+func NewMockContextFromRequest(req *http.Request) *MockContext {
+	var c easyserver.Context
+	e := NewTestEngine()
+	// 在路由之前取得Context的快照, 快照在请求处理结束后仍然可用
+	e.AppendMiddleware(func(ctx easyserver.Context) {
+		c = ctx.Copy()
+		ctx.Abort()
+	})
+	w := httptest.NewRecorder()
+	e.(http.Handler).ServeHTTP(w, req)
+	return &MockContext{
+		Context:  c,
+		Recorder: w,
+	}
+}
+
+func (m *MockContext) Next() bool {
+	m.NextCalls++
+	if m.NextFunc != nil {
+		return m.NextFunc()
+	}
+	return !m.IsAborted()
+}
+
+func (m *MockContext) GetParamParam() []router.UrlParam {
+	if m.Params != nil {
+		return m.Params
+	}
+	return m.Context.GetParamParam()
+}
+
+// AssertNextCalled Next未被调用时使t失败
+func (m *MockContext) AssertNextCalled(t testing.TB) {
+	t.Helper()
+	if m.NextCalls == 0 {
+		t.Errorf("expected Next to be called, but it was not")
+	}
+}
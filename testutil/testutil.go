@@ -0,0 +1,49 @@
+// Package testutil 提供测试Engine及handler、中间件的辅助函数
+package testutil
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gogokit/easyserver"
+)
+
+// NewTestEngine 返回关闭了请求日志且不输出任何日志的Engine
+func NewTestEngine() easyserver.Engine {
+	e := easyserver.New()
+	e.SetRequestLogging(false)
+	e.SetLogger(nopLogger{})
+	return e
+}
+
+// TestRequest 使用httptest.NewRecorder在e上处理一个请求并返回响应, body可为nil
+func TestRequest(e easyserver.Engine, method, path string, body io.Reader, headers map[string]string) *http.Response {
+	req := httptest.NewRequest(method, path, body)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	e.(http.Handler).ServeHTTP(w, req)
+	return w.Result()
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Trace(ctx context.Context, msg string, args ...interface{})    {}
+func (nopLogger) Debug(ctx context.Context, msg string, args ...interface{})    {}
+func (nopLogger) Info(ctx context.Context, msg string, args ...interface{})     {}
+func (nopLogger) Warn(ctx context.Context, msg string, args ...interface{})     {}
+func (nopLogger) Error(ctx context.Context, msg string, args ...interface{})    {}
+func (nopLogger) Critical(ctx context.Context, msg string, args ...interface{}) {}
+
+// ServeTest 返回使用e处理请求的httptest.Server, 使用完毕后需调用Close
+func ServeTest(e easyserver.Engine) *httptest.Server {
+	return httptest.NewServer(e.(http.Handler))
+}
+
+// ServeTLSTest 同ServeTest, 但使用https, 可通过返回值的Client方法获取信任其证书的http.Client
+func ServeTLSTest(e easyserver.Engine) *httptest.Server {
+	return httptest.NewTLSServer(e.(http.Handler))
+}
@@ -0,0 +1,53 @@
+package easyserver
+
+import (
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+)
+
+// 解析multipart表单时内存中最多保存的字节数, 超出部分保存在临时文件中
+const defaultMultipartMemory = 32 << 20
+
+func (c *reqContext) FormFile(name string) (*multipart.FileHeader, error) {
+	if c.req.MultipartForm == nil {
+		if err := c.req.ParseMultipartForm(defaultMultipartMemory); err != nil {
+			return nil, err
+		}
+	}
+	f, fh, err := c.req.FormFile(name)
+	if err != nil {
+		return nil, err
+	}
+	_ = f.Close()
+	return fh, nil
+}
+
+func (c *reqContext) MultipartForm() (*multipart.Form, error) {
+	if err := c.req.ParseMultipartForm(defaultMultipartMemory); err != nil {
+		return nil, err
+	}
+	return c.req.MultipartForm, nil
+}
+
+func (c *reqContext) SaveUploadedFile(fh *multipart.FileHeader, dst string) error {
+	src, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err = os.MkdirAll(filepath.Dir(dst), 0750); err != nil {
+		return err
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, src)
+	return err
+}
@@ -0,0 +1,50 @@
+package easyserver
+
+import (
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// VaryMiddleware 返回将headers追加到Vary响应头的中间件, 不会覆盖其他中间件或handler已设置的值, 重复的请求头(不区分大小写)只保留一个,
+// Vary已为"*"时不再追加
+func VaryMiddleware(headers ...string) func(c Context) {
+	canonical := make([]string, 0, len(headers))
+	for _, h := range headers {
+		canonical = append(canonical, textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(h)))
+	}
+	return func(c Context) {
+		addVary(c.GetResp().Header(), canonical...)
+		c.Next()
+	}
+}
+
+// addVary 将headers合并到header的Vary中, 合并后的值只占一行
+func addVary(header http.Header, headers ...string) {
+	var values []string
+	for _, line := range header.Values("Vary") {
+		for _, v := range strings.Split(line, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	for _, h := range headers {
+		if h == "" {
+			continue
+		}
+		found := false
+		for _, v := range values {
+			if v == "*" || strings.EqualFold(v, h) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			values = append(values, h)
+		}
+	}
+	if len(values) > 0 {
+		header.Set("Vary", strings.Join(values, ", "))
+	}
+}